@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/ahmaruff/go-fleet/internal/display"
+	"github.com/ahmaruff/go-fleet/internal/protocol"
+)
+
+// how many chat lines stay on screen under the board
+const chatLines = 6
+
+// chat keeps the latest lines, so redrawing the board doesn't wipe them
+var chat struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+// chatLine formats msg for the screen, lobby and spectator chat marked as such
+func chatLine(msg protocol.Chat) string {
+	line := fmt.Sprintf("%s%s:%s %s", display.Blue, msg.From, display.Reset, msg.Text)
+	switch msg.Channel {
+	case protocol.ChannelLobby:
+		line = display.Yellow + "[lobby] " + display.Reset + line
+	case protocol.ChannelSpectators:
+		line = display.Yellow + "[spectators] " + display.Reset + line
+	}
+	return line
+}
+
+// addChat remembers msg and returns it formatted for the screen
+func addChat(msg protocol.Chat) string {
+	line := chatLine(msg)
+
+	chat.mu.Lock()
+	defer chat.mu.Unlock()
+
+	chat.lines = append(chat.lines, line)
+	if len(chat.lines) > chatLines {
+		chat.lines = chat.lines[len(chat.lines)-chatLines:]
+	}
+
+	return line
+}
+
+// chatRegion is the chat box drawn under every screen, empty until someone talks
+func chatRegion() string {
+	chat.mu.Lock()
+	defer chat.mu.Unlock()
+
+	if len(chat.lines) == 0 {
+		return ""
+	}
+
+	var region strings.Builder
+	region.WriteString("\n------------------ CHAT (/say <message>) ------------------\n")
+	for _, line := range chat.lines {
+		region.WriteString(line + "\n")
+	}
+
+	return region.String()
+}
+
+// showScreen clears the terminal and draws screen with the chat region under it
+func showScreen(screen string) {
+	clearBoard()
+
+	screen += chatRegion()
+	display.ClearScreen()
+	fmt.Print(screen)
+	addLines(screen)
+}
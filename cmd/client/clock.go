@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ahmaruff/go-fleet/internal/display"
+	"github.com/ahmaruff/go-fleet/internal/game"
+)
+
+// board is the game on screen in line mode. Its header is rewritten in place
+// once a second so the clock ticks down without wiping what's being typed.
+var board struct {
+	mu     sync.Mutex
+	view   *game.View // nil while the screen shows something else
+	at     time.Time  // when view arrived
+	header int        // length of the header as last drawn
+	lines  int        // rows used since the screen was cleared
+}
+
+// timeLeft is v's clock, counted down from when it arrived
+func timeLeft(v game.View, at time.Time) int {
+	if v.TimeLeft == 0 {
+		return 0
+	}
+	return max(v.TimeLeft-int(time.Since(at).Seconds()), 1)
+}
+
+// showBoard draws v, which arrived at at, and keeps its clock ticking
+func showBoard(v game.View, at time.Time) {
+	v.TimeLeft = timeLeft(v, at)
+	showScreen(display.RenderView(v))
+
+	board.mu.Lock()
+	defer board.mu.Unlock()
+
+	board.view, board.at = &v, time.Now()
+	board.header = utf8.RuneCountInString(display.Header(v))
+}
+
+// printLine prints text on its own line, keeping count of the rows it takes
+func printLine(text string) {
+	rows := countRows(text + "\n")
+
+	// never in the middle of a clock rewrite
+	board.mu.Lock()
+	defer board.mu.Unlock()
+
+	fmt.Println(text)
+	board.lines += rows
+}
+
+// addLines counts text, already on screen, against the rows left under the header
+func addLines(text string) {
+	rows := countRows(text)
+
+	board.mu.Lock()
+	defer board.mu.Unlock()
+
+	board.lines += rows
+}
+
+// colours strips the colour codes we print, they take no room on screen
+var colours = strings.NewReplacer(display.Reset, "", display.Blue, "", display.Green, "", display.Red, "", display.Yellow, "")
+
+// countRows is how many rows text takes once printed, wrapping included
+func countRows(text string) int {
+	width := 0
+	if w, _, err := (&terminal{}).size(); err == nil {
+		width = w
+	}
+
+	rows := 0
+	for _, line := range strings.SplitAfter(colours.Replace(text), "\n") {
+		if !strings.HasSuffix(line, "\n") {
+			continue
+		}
+		n := utf8.RuneCountInString(strings.TrimSuffix(line, "\n"))
+		if width > 0 && n > width {
+			rows += (n + width - 1) / width
+		} else {
+			rows++
+		}
+	}
+	return rows
+}
+
+// clearBoard forgets the board once something else takes the screen
+func clearBoard() {
+	board.mu.Lock()
+	defer board.mu.Unlock()
+
+	board.view, board.lines = nil, 0
+}
+
+// tickClock rewrites the board's header every second while its clock runs
+func tickClock() {
+	for range time.Tick(time.Second) {
+		board.mu.Lock()
+		v := board.view
+		if v == nil || v.TimeLeft == 0 {
+			board.mu.Unlock()
+			continue
+		}
+
+		ticked := *v
+		ticked.TimeLeft = timeLeft(*v, board.at)
+
+		// the header sits on the second row, gone once the screen has scrolled
+		_, height, err := (&terminal{}).size()
+		if err != nil || board.lines >= height || ticked.TimeLeft == v.TimeLeft {
+			board.mu.Unlock()
+			continue
+		}
+
+		header := display.Header(ticked)
+		// padded so a shorter clock covers the longer one
+		header += strings.Repeat(" ", max(board.header-utf8.RuneCountInString(header), 0))
+		fmt.Print("\0337\033[2;1H" + header + "\0338")
+		board.mu.Unlock()
+	}
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ahmaruff/go-fleet/internal/protocol"
+)
+
+// reconnect backoff: 500ms, 1s, 2s, ... capped at 10s between attempts
+const (
+	initialBackoff = 500 * time.Millisecond
+	maxBackoff     = 10 * time.Second
+)
+
+var errNotConnected = errors.New("not connected to server")
+
+// connection keeps a link to the server alive, re-dialing with backoff and
+// resuming the session whenever the link drops
+type connection struct {
+	address    string
+	maxRetries int
+
+	mu         sync.Mutex
+	conn       net.Conn
+	encoder    *protocol.Encoder
+	decoder    *protocol.Decoder
+	token      string // session token from the server, empty until /name or /login
+	name       string
+	registered bool // logged in to an account, can't come back as a guest
+	closing    bool
+
+	// info shows connection news such as reconnect attempts, printed when nil
+	info func(string)
+}
+
+func dial(address string, maxRetries int) (*connection, protocol.Welcome, error) {
+	c := &connection{address: address, maxRetries: maxRetries}
+	welcome, err := c.connect()
+	return c, welcome, err
+}
+
+func (c *connection) connect() (protocol.Welcome, error) {
+	conn, err := net.DialTimeout("tcp", c.address, 5*time.Second)
+	if err != nil {
+		return protocol.Welcome{}, err
+	}
+
+	encoder := protocol.NewEncoder(conn)
+	decoder := protocol.NewDecoder(conn)
+
+	c.mu.Lock()
+	token := c.token
+	c.mu.Unlock()
+
+	welcome, err := protocol.ClientHandshake(encoder, decoder, token)
+	if err != nil {
+		conn.Close()
+		return protocol.Welcome{}, err
+	}
+
+	c.mu.Lock()
+	c.conn = conn
+	c.encoder = encoder
+	c.decoder = decoder
+	c.mu.Unlock()
+
+	return welcome, nil
+}
+
+func (c *connection) send(command protocol.Command) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.encoder == nil {
+		return errNotConnected
+	}
+
+	if command.Name == "/name" && len(command.Args) > 0 {
+		c.name = strings.Join(command.Args, " ")
+	}
+
+	return c.encoder.Encode(command)
+}
+
+func (c *connection) notify(text string) {
+	if c.info != nil {
+		c.info(text)
+		return
+	}
+	printLine(text)
+}
+
+func (c *connection) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closing = true
+	if c.conn != nil {
+		c.conn.Close()
+	}
+}
+
+// listen hands every server message to handle, reconnecting as needed, until
+// close is called or the server can't be reached again
+func (c *connection) listen(handle func(protocol.Message)) error {
+	for {
+		c.mu.Lock()
+		decoder := c.decoder
+		c.mu.Unlock()
+
+		for {
+			m, err := decoder.Decode()
+			if errors.Is(err, protocol.ErrMalformed) {
+				continue
+			}
+
+			if err != nil {
+				break
+			}
+
+			c.intercept(m)
+			handle(m)
+		}
+
+		c.mu.Lock()
+		closing := c.closing
+		c.conn.Close()
+		c.encoder = nil
+		c.mu.Unlock()
+
+		if closing {
+			return nil
+		}
+
+		if err := c.reconnect(); err != nil {
+			return err
+		}
+	}
+}
+
+// intercept keeps the session bookkeeping the rest of the client doesn't need to know about
+func (c *connection) intercept(m protocol.Message) {
+	switch msg := m.(type) {
+	case protocol.Session:
+		c.mu.Lock()
+		c.token = msg.Token
+		c.name = msg.Name
+		c.registered = msg.Registered
+		c.mu.Unlock()
+
+	case protocol.Error:
+		if msg.Code != protocol.CodeSessionExpired {
+			return
+		}
+
+		// the match is gone, come back as a fresh player under the same name
+		c.mu.Lock()
+		c.token = ""
+		name, registered := c.name, c.registered
+		c.mu.Unlock()
+
+		if registered {
+			c.notify("[INFO] - Session expired, /login again to continue")
+			return
+		}
+
+		if name != "" {
+			c.send(protocol.Command{Name: "/name", Args: []string{name}})
+		}
+	}
+}
+
+func (c *connection) reconnect() error {
+	backoff := initialBackoff
+	for attempt := 1; c.maxRetries <= 0 || attempt <= c.maxRetries; attempt++ {
+		c.notify(fmt.Sprintf("[INFO] - Connection lost, reconnecting in %s (attempt %d)...", backoff, attempt))
+		time.Sleep(backoff)
+
+		_, err := c.connect()
+		if err == nil {
+			c.notify("[INFO] - Reconnected!")
+			c.resumeAsGuest()
+			return nil
+		}
+
+		backoff = min(backoff*2, maxBackoff)
+	}
+
+	return errors.New("could not reconnect to server at " + c.address)
+}
+
+// resumeAsGuest re-sends /name when there's no token to resume, e.g. before the server issued one
+func (c *connection) resumeAsGuest() {
+	c.mu.Lock()
+	token, name := c.token, c.name
+	c.mu.Unlock()
+
+	if token == "" && name != "" {
+		c.send(protocol.Command{Name: "/name", Args: []string{name}})
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+
+	"github.com/ahmaruff/go-fleet/internal/display"
+	"github.com/ahmaruff/go-fleet/internal/effects"
+	"github.com/ahmaruff/go-fleet/internal/game"
+	"github.com/ahmaruff/go-fleet/internal/protocol"
+)
+
+// playHotSeat runs a whole match between two people sharing this terminal,
+// no server involved. Between turns a pass the keyboard screen hides the
+// board of whoever just played.
+func playHotSeat(scanner *bufio.Scanner, names [2]string) {
+	r := rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+
+	players := [2]*game.Player{{Name: names[0]}, {Name: names[1]}}
+	g := game.NewGame(players[0], players[1])
+
+	// fleets are placed one after the other, each out of the other's sight
+	for _, p := range players {
+		if !passKeyboard(scanner, p.Name, "place your fleet") {
+			return
+		}
+
+		message := "Place your fleet with /place <ship> <coord> <H|V>, or /random to let the computer do it, then /confirm"
+		for !g.Confirmed(p) {
+			command, ok := turnPrompt(scanner, g, p, message)
+			if !ok {
+				return
+			}
+
+			switch strings.ToLower(command.Name) {
+			case "/place", "/set", "/random", "/unset", "/remove", "/clear", "/confirm":
+				message = placementCommand(g, p, r, command)
+			default:
+				message = "[ERROR] - Unknown command, use /place, /random, /unset, /clear, /confirm or /quit"
+			}
+		}
+	}
+
+	// what the last shot did, for the player it was fired at
+	report := ""
+
+	for {
+		shooter := players[g.CurrPlayer-1]
+		if !passKeyboard(scanner, shooter.Name, "take your turn") {
+			return
+		}
+
+		message := report + "Your turn, /fire <coord> to fire"
+		var result game.ShotResult
+
+		for {
+			command, ok := turnPrompt(scanner, g, shooter, message)
+			if !ok {
+				return
+			}
+
+			if strings.ToLower(command.Name) != "/fire" {
+				message = "[ERROR] - Unknown command, use /fire or /quit"
+				continue
+			}
+			if len(command.Args) < 1 {
+				message = "[ERROR] - Usage: /fire A1"
+				continue
+			}
+
+			var err error
+			result, err = g.FireAtOpponent(shooter, command.Args[0])
+			if err != nil {
+				message = "[ERROR] - Invalid shot at " + command.Args[0] + ": " + err.Error()
+				continue
+			}
+			break
+		}
+
+		shot := describeShot(result)
+
+		if winner, over := g.IsGameOver(); over {
+			// nothing left to hide, show both fleets
+			display.ClearScreen()
+			fmt.Print(display.RenderGameRevealedAsString(g))
+			fmt.Println()
+			fmt.Println("[SHOT_RESULT] - " + shot)
+			fmt.Println(effects.GetEffect("VICTORY"))
+			fmt.Printf("[GAME_OVER] - %s wins!\n", players[winner-1].Name)
+			return
+		}
+
+		// the shooter sees the shot land before handing over
+		display.ClearScreen()
+		fmt.Print(display.RenderView(g.ViewFor(shooter)))
+		fmt.Println()
+		fmt.Println("[SHOT_RESULT] - " + shot)
+		fmt.Print(">> Press Enter to end your turn ")
+		if !scanner.Scan() {
+			return
+		}
+
+		report = "[OPPONENT_SHOT] - " + shooter.Name + ": " + shot + "\n"
+	}
+}
+
+// passKeyboard hides the board until the next player is at the keyboard,
+// false once input runs out
+func passKeyboard(scanner *bufio.Scanner, name, task string) bool {
+	display.ClearScreen()
+	// the scrollback still holds the last board, clear that too
+	fmt.Print("\033[3J")
+
+	fmt.Println("========================== PASS THE KEYBOARD ==========================")
+	fmt.Printf("Hand the keyboard to %s, no peeking!\n", name)
+	fmt.Println("=======================================================================")
+	fmt.Println()
+	fmt.Printf(">> %s, press Enter to %s ", name, task)
+
+	return scanner.Scan()
+}
+
+// turnPrompt shows p's view of the game with message under it and reads a
+// command, false when the players quit or input runs out
+func turnPrompt(scanner *bufio.Scanner, g *game.Game, p *game.Player, message string) (protocol.Command, bool) {
+	for {
+		display.ClearScreen()
+		fmt.Print(display.RenderView(g.ViewFor(p)))
+		fmt.Println()
+		fmt.Println(message)
+		fmt.Print(">> ")
+
+		if !scanner.Scan() {
+			return protocol.Command{}, false
+		}
+
+		command, ok := protocol.ParseCommand(scanner.Text())
+		if !ok {
+			continue
+		}
+
+		switch strings.ToLower(command.Name) {
+		case "/quit", "/exit", "quit":
+			return protocol.Command{}, false
+		}
+		return command, true
+	}
+}
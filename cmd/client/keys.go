@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"unicode/utf8"
+)
+
+type keyKind int
+
+const (
+	keyRune keyKind = iota // a typed character, in key.r
+	keyUp
+	keyDown
+	keyLeft
+	keyRight
+	keyEnter
+	keyBackspace
+	keyEscape
+	keyPageUp
+	keyPageDown
+	keyClearLine // Ctrl-U
+	keyQuit      // Ctrl-C or Ctrl-D
+	keyUnknown   // a function key we have no use for
+)
+
+type key struct {
+	kind keyKind
+	r    rune
+}
+
+// escape sequences the arrow and page keys send, in both cursor key modes
+var sequences = map[string]keyKind{
+	"\x1b[A":  keyUp,
+	"\x1b[B":  keyDown,
+	"\x1b[C":  keyRight,
+	"\x1b[D":  keyLeft,
+	"\x1bOA":  keyUp,
+	"\x1bOB":  keyDown,
+	"\x1bOC":  keyRight,
+	"\x1bOD":  keyLeft,
+	"\x1b[5~": keyPageUp,
+	"\x1b[6~": keyPageDown,
+}
+
+// readKeys decodes raw terminal input into keys until it runs out
+func readKeys(r io.Reader, keys chan<- key) {
+	defer close(keys)
+
+	buf := make([]byte, 256)
+	for {
+		n, err := r.Read(buf)
+		for _, k := range parseKeys(buf[:n]) {
+			keys <- k
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// parseKeys splits one read into keys. A terminal writes an escape sequence
+// in one go, so an ESC at the end of a read is the Escape key itself.
+func parseKeys(b []byte) []key {
+	var keys []key
+
+	for len(b) > 0 {
+		if b[0] == 0x1b {
+			kind, size := parseSequence(b)
+			if size > 0 {
+				keys = append(keys, key{kind: kind})
+				b = b[size:]
+				continue
+			}
+		}
+
+		switch b[0] {
+		case '\r', '\n':
+			keys = append(keys, key{kind: keyEnter})
+		case 0x7f, 0x08:
+			keys = append(keys, key{kind: keyBackspace})
+		case 0x1b:
+			keys = append(keys, key{kind: keyEscape})
+		case 0x15:
+			keys = append(keys, key{kind: keyClearLine})
+		case 0x03, 0x04:
+			keys = append(keys, key{kind: keyQuit})
+		default:
+			r, size := utf8.DecodeRune(b)
+			if r >= ' ' && r != utf8.RuneError {
+				keys = append(keys, key{kind: keyRune, r: r})
+			}
+			b = b[size:]
+			continue
+		}
+		b = b[1:]
+	}
+
+	return keys
+}
+
+// parseSequence matches a known escape sequence at the start of b, size is 0 when there's none
+func parseSequence(b []byte) (kind keyKind, size int) {
+	for seq, kind := range sequences {
+		if bytes.HasPrefix(b, []byte(seq)) {
+			return kind, len(seq)
+		}
+	}
+
+	// an unknown CSI sequence such as F5 or Home, skipped whole
+	if len(b) > 2 && b[1] == '[' {
+		for i := 2; i < len(b); i++ {
+			if b[i] >= 0x40 && b[i] <= 0x7e {
+				return keyUnknown, i + 1
+			}
+		}
+	}
+
+	return keyEscape, 0
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+
+	"github.com/ahmaruff/go-fleet/internal/ai"
+	"github.com/ahmaruff/go-fleet/internal/display"
+	"github.com/ahmaruff/go-fleet/internal/effects"
+	"github.com/ahmaruff/go-fleet/internal/game"
+	"github.com/ahmaruff/go-fleet/internal/protocol"
+)
+
+// playVsAI runs a whole match in this terminal against the computer, no server involved
+func playVsAI(scanner *bufio.Scanner, playerName, levelName string) error {
+	level, err := ai.ParseLevel(levelName)
+	if err != nil {
+		return err
+	}
+
+	r := rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+
+	human := &game.Player{Name: playerName}
+	computer := &game.Player{Name: "Computer (" + string(level) + ")"}
+
+	g := game.NewGame(human, computer)
+	g.PlaceRandomFleetForPlayer(computer, r)
+	g.ConfirmFleet(computer)
+	strategy := ai.New(level, r)
+
+	message := "Place your fleet with /place <ship> <coord> <H|V>, or /random to let the computer do it, then /confirm"
+
+	for {
+		display.ClearScreen()
+		fmt.Print(display.RenderView(g.ViewFor(human)))
+		fmt.Println()
+		fmt.Println(message)
+
+		if winner, over := g.IsGameOver(); over && g.Phase == game.PhaseFinished {
+			if winner == 1 {
+				fmt.Println(effects.GetEffect("VICTORY"))
+			} else {
+				fmt.Println(effects.GetEffect("DEFEAT"))
+			}
+			return nil
+		}
+
+		fmt.Print(">> ")
+		if !scanner.Scan() {
+			return nil
+		}
+
+		command, ok := protocol.ParseCommand(scanner.Text())
+		if !ok {
+			continue
+		}
+
+		switch strings.ToLower(command.Name) {
+		case "/quit", "/exit", "quit":
+			return nil
+
+		case "/place", "/set", "/random", "/unset", "/remove", "/clear", "/confirm":
+			message = placementCommand(g, human, r, command)
+
+		case "/fire":
+			if g.Phase != game.PhasePlaying {
+				message = "[ERROR] - Not in combat phase"
+				continue
+			}
+			if len(command.Args) < 1 {
+				message = "[ERROR] - Usage: /fire A1"
+				continue
+			}
+
+			result, err := g.FireAtOpponent(human, command.Args[0])
+			if err != nil {
+				message = "[ERROR] - Invalid shot at " + command.Args[0] + ": " + err.Error()
+				continue
+			}
+			message = "[SHOT_RESULT] - " + describeShot(result)
+
+			if g.Phase != game.PhasePlaying {
+				continue
+			}
+
+			// the computer answers right away
+			row, col := strategy.NextShot(g.ViewFor(computer).Opponent)
+			result, _ = g.FireAtOpponent(computer, game.CellName(row, col))
+			message += "\n[OPPONENT_SHOT] - " + computer.Name + ": " + describeShot(result)
+
+		default:
+			message = "[ERROR] - Unknown command, use /place, /random, /unset, /clear, /confirm, /fire or /quit"
+		}
+	}
+}
+
+// placementCommand runs a fleet placement command for p and says how it went
+func placementCommand(g *game.Game, p *game.Player, r *rand.Rand, command protocol.Command) string {
+	switch strings.ToLower(command.Name) {
+	case "/place", "/set":
+		if !g.Phase.Placing() {
+			return "[ERROR] - Not in placement phase"
+		}
+		if len(command.Args) < 3 {
+			return "[ERROR] - Usage: /place <ship> <coord> <H|V> (e.g. /place Carrier A1 H)"
+		}
+
+		err := g.PlaceShipForPlayer(p, command.Args[0], command.Args[1], command.Args[2])
+		if err != nil {
+			return "[ERROR] - " + err.Error()
+		}
+		return "[SHIP_PLACED] - " + command.Args[0] + " placed at " + strings.ToUpper(command.Args[1])
+
+	case "/random":
+		if !g.Phase.Placing() {
+			return "[ERROR] - Not in placement phase"
+		}
+
+		if err := g.PlaceRandomFleetForPlayer(p, r); err != nil {
+			return "[ERROR] - " + err.Error()
+		}
+		return "[SHIP_PLACED] - Fleet placed at random, /confirm to lock it in"
+
+	case "/unset", "/remove":
+		if len(command.Args) != 1 {
+			return "[ERROR] - Usage: /unset <coord> or /remove <ship>"
+		}
+
+		var ship game.Ship
+		var err error
+		if strings.ToLower(command.Name) == "/unset" {
+			ship, err = g.RemoveShipAtForPlayer(p, command.Args[0])
+		} else {
+			ship, err = g.RemoveShipForPlayer(p, command.Args[0])
+		}
+		if err != nil {
+			return "[ERROR] - " + err.Error()
+		}
+		return "[SHIP_REMOVED] - " + ship.Class.Name + " taken back"
+
+	case "/clear":
+		if err := g.ClearFleetForPlayer(p); err != nil {
+			return "[ERROR] - " + err.Error()
+		}
+		return "[FLEET_CLEARED] - All ships taken back"
+
+	case "/confirm":
+		if err := g.ConfirmFleet(p); err != nil {
+			return "[ERROR] - " + err.Error()
+		}
+		if g.Phase == game.PhasePlaying {
+			return "[COMBAT_START] - Fleet locked in! Combat phase begins!"
+		}
+		return "[FLEET_CONFIRMED] - Fleet locked in!"
+	}
+
+	return "[ERROR] - Unknown command " + command.Name
+}
+
+func describeShot(result game.ShotResult) string {
+	cell := game.CellName(result.Row, result.Col)
+	switch result.Outcome {
+	case game.Sunk:
+		return fmt.Sprintf("HIT at %s, %s sunk!", cell, result.Ship.Class.Name)
+	case game.Hit:
+		return "HIT at " + cell
+	default:
+		return "MISS at " + cell
+	}
+}
@@ -2,16 +2,18 @@ package main
 
 import (
 	"bufio"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
-	"net"
 	"os"
 	"strings"
 	"time"
 
 	"github.com/ahmaruff/go-fleet/internal/display"
 	"github.com/ahmaruff/go-fleet/internal/effects"
+	"github.com/ahmaruff/go-fleet/internal/game"
+	"github.com/ahmaruff/go-fleet/internal/protocol"
 )
 
 func main() {
@@ -25,178 +27,219 @@ func main() {
 	// Command line flags
 	host := flag.String("host", "localhost", "Server host")
 	port := flag.String("port", "8080", "Server port")
+	retries := flag.Int("retries", 10, "Reconnect attempts after losing the server (0 = keep trying)")
+	vsAI := flag.String("vs-ai", "", "Play offline against the computer: easy, medium or hard")
+	local := flag.Bool("local", false, "Two players take turns at this terminal, no server needed")
+	plain := flag.Bool("plain", false, "Type every command on a plain line instead of the full-screen interface")
 	flag.Parse()
 
+	scanner := bufio.NewScanner(os.Stdin)
+
+	if *vsAI != "" {
+		fmt.Print(">> Please enter your name: ")
+		scanner.Scan()
+
+		if err := playVsAI(scanner, scanner.Text(), *vsAI); err != nil {
+			log.Fatal("[ERROR] - ", err)
+		}
+		return
+	}
+
+	if *local {
+		var names [2]string
+		for i := range names {
+			fmt.Printf(">> Player %d, please enter your name: ", i+1)
+			scanner.Scan()
+
+			names[i] = strings.TrimSpace(scanner.Text())
+			if names[i] == "" {
+				names[i] = fmt.Sprintf("Player %d", i+1)
+			}
+		}
+
+		playHotSeat(scanner, names)
+		return
+	}
+
 	address := *host + ":" + *port
 	fmt.Printf("[INFO] - Connecting to Go-Fleet Server at %s...\n", address)
 
 	// Connect to server
-	conn, err := net.Dial("tcp", address)
+	conn, welcome, err := dial(address, *retries)
 	if err != nil {
 		log.Fatal("[ERROR] - Failed to connect to server:", err)
 	}
-	defer conn.Close()
+	defer conn.close()
+
+	// full-screen whenever stdin is a terminal that can go raw
+	if !*plain {
+		if term, err := makeRaw(); err == nil {
+			if err := runTUI(conn, term, welcome); err != nil {
+				log.Fatal("[ERROR] - ", err)
+			}
+			return
+		}
+	}
 
-	fmt.Println("[INFO] - Connected!")
+	fmt.Printf("[INFO] - Connected! (protocol v%d)\n", welcome.Version)
 
-	// Ask for player name
+	// Ask for player name, registered players log in instead
+	fmt.Println("[INFO] - Have an account? /login <name> <password>, or /register <name> <password> to make one")
 	fmt.Print(">> Please enter your name: ")
-	scanner := bufio.NewScanner(os.Stdin)
 	scanner.Scan()
 	playerName := scanner.Text()
 
+	command, ok := protocol.ParseCommand(playerName)
+	if !ok || !strings.HasPrefix(command.Name, "/") {
+		command = protocol.Command{Name: "/name", Args: strings.Fields(playerName)}
+	}
+
 	// Send name to server
-	_, err = conn.Write([]byte("/name " + playerName + "\n"))
+	err = conn.send(command)
 	if err != nil {
 		log.Fatal("[ERROR] - Failed to send name:", err)
 	}
 
 	// Start listening for server messages
-	go listenForMessages(conn)
+	go func() {
+		if err := conn.listen(handleMessage); err != nil {
+			log.Fatal("[ERROR] - ", err)
+		}
+	}()
 
 	// Small delay to let server response come through
 	time.Sleep(100 * time.Millisecond)
 
-	display.ClearScreen()
+	showReadyPrompt()
 
-	fmt.Println("============================== GO-FLEET ==============================")
-	fmt.Println("Type '/ready' if you're ready for war or '/quit' to exit")
-	fmt.Println("======================================================================")
-	fmt.Println()
+	go tickClock()
 
 	// Continue with existing input loop...
 	for scanner.Scan() {
 		message := scanner.Text()
+		// the terminal echoed it, a row further from the board's clock
+		addLines(message + "\n")
 
 		if message == "quit" || message == "/quit" || message == "/exit" {
 			break
 		}
-		_, err := conn.Write([]byte(message + "\n"))
+
+		command, ok := protocol.ParseCommand(message)
+		if !ok {
+			continue
+		}
+
+		err := conn.send(command)
+		if errors.Is(err, errNotConnected) {
+			printLine("[INFO] - Not connected right now, try again once reconnected")
+			continue
+		}
+
 		if err != nil {
 			log.Println("[ERROR] - Failed to send message:", err)
-			break
 		}
 	}
 }
 
 var effectQueue []string
 var currentlyShowingEffect bool
-var queuedDisplay string
-
-func listenForMessages(conn net.Conn) {
-	scanner := bufio.NewScanner(conn)
-	var displayBuffer strings.Builder
-	var effectBuffer strings.Builder
+var queuedView *game.View
+var queuedAt time.Time
 
-	inDisplayMode := false
-	inEffectMode := false
+func handleMessage(m protocol.Message) {
+	switch msg := m.(type) {
+	case protocol.Effect:
+		effectQueue = append(effectQueue, effects.GetEffect(msg.Name)+"\n")
 
-	for scanner.Scan() {
-		line := scanner.Text()
-
-		if line == "\n" {
-			continue
+		if !currentlyShowingEffect {
+			showNextEffect()
 		}
 
-		if line == "OPPONENT_DISCONNECTED" {
-			// Clear all game state
-			effectQueue = nil
-			currentlyShowingEffect = false
-			queuedDisplay = ""
-
-			fmt.Println("---------------------")
-			fmt.Println("Opponent Disconected!")
-			fmt.Println("---------------------")
+	case protocol.State:
+		// boards arrive as data, rendering is up to us
+		queuedView, queuedAt = &msg.View, time.Now()
 
-			time.Sleep(3 * time.Second)
-
-			// Show ready prompt
-			display.ClearScreen()
-
-			fmt.Println("============================== GO-FLEET ==============================")
-			fmt.Println("Type '/ready' if you're ready for war or '/quit' to exit")
-			fmt.Println("======================================================================")
-			fmt.Println()
-			continue
+		// If no effects are showing, display immediately
+		if !currentlyShowingEffect {
+			showBoard(*queuedView, queuedAt)
+			queuedView = nil
 		}
 
-		if line == "GAME_RESET" {
-			// Clear all game state
-			effectQueue = nil
-			currentlyShowingEffect = false
-			queuedDisplay = ""
+	case protocol.Chat:
+		line := addChat(msg)
 
-			// Show ready prompt
-			display.ClearScreen()
-
-			fmt.Println("============================== GO-FLEET ==============================")
-			fmt.Println("Type '/ready' if you're ready for war or '/quit' to exit")
-			fmt.Println("======================================================================")
-			fmt.Println()
-			continue
+		// shown right away, and again under every redraw until it scrolls off
+		if !currentlyShowingEffect {
+			printLine(line)
 		}
 
-		if line == "EFFECT_UPDATE" {
-			//			fmt.Printf("[DEBUG] Starting effect mode\n")
-			inEffectMode = true
-			effectBuffer.Reset()
-			continue
+	case protocol.Error:
+		if !currentlyShowingEffect {
+			printLine("[ERROR] - " + msg.Text)
 		}
 
-		if line == "EFFECT_END" {
-			inEffectMode = false
-			effectQueue = append(effectQueue, effectBuffer.String())
+	case protocol.Notice:
+		handleNotice(msg)
+	}
+}
 
-			if !currentlyShowingEffect {
-				showNextEffect()
-			}
-			continue
-		}
+func handleNotice(msg protocol.Notice) {
+	switch msg.Tag {
+	case "OPPONENT_DISCONNECTED":
+		// Clear all game state
+		effectQueue = nil
+		currentlyShowingEffect = false
+		queuedView = nil
 
-		if inEffectMode {
-			effectBuffer.WriteString(line + "\n")
-			continue
-		}
+		clearBoard()
+		fmt.Println("---------------------")
+		fmt.Println("Opponent Disconected!")
+		fmt.Println("---------------------")
 
-		if line == "DISPLAY_UPDATE" {
-			inDisplayMode = true
-			displayBuffer.Reset()
-			continue
-		}
+		time.Sleep(3 * time.Second)
 
-		if line == "END_DISPLAY" {
-			inDisplayMode = false
-			queuedDisplay = displayBuffer.String()
+		showReadyPrompt()
 
-			// If no effects are showing, display immediately
-			if !currentlyShowingEffect {
-				display.ClearScreen()
-				fmt.Print(queuedDisplay)
-				queuedDisplay = ""
-			}
-			continue
-		}
+	case "GAME_RESET", "WATCH_END":
+		// Clear all game state
+		effectQueue = nil
+		currentlyShowingEffect = false
+		queuedView = nil
 
-		if inDisplayMode {
-			displayBuffer.WriteString(line + "\n")
-			continue
-		}
+		showReadyPrompt()
 
+	case "GAME_OVER":
+		// the clock stops with the game
+		clearBoard()
+		printLine("======================================")
+		printLine("[GAME_OVER] - " + msg.Text)
+		printLine("======================================")
+
+	default:
 		// Regular server messages
 		if !currentlyShowingEffect {
-			fmt.Printf("%s\n", line)
+			printLine(fmt.Sprintf("[%s] - %s", msg.Tag, msg.Text))
 		}
 	}
 }
 
+func showReadyPrompt() {
+	showScreen(`============================== GO-FLEET ==============================
+Type '/ready' if you're ready for war or '/quit' to exit
+Playing a friend? '/create-room' gives you a code for them to '/join'
+Say hello to the lobby with '/say <message>'
+======================================================================
+
+`)
+}
+
 func showNextEffect() {
 	if len(effectQueue) == 0 {
 		currentlyShowingEffect = false
 		// Show queued display if available
-		if queuedDisplay != "" {
-			display.ClearScreen()
-			fmt.Print(queuedDisplay)
-			queuedDisplay = ""
+		if queuedView != nil {
+			showBoard(*queuedView, queuedAt)
+			queuedView = nil
 		}
 		return
 	}
@@ -205,6 +248,7 @@ func showNextEffect() {
 	effect := effectQueue[0]
 	effectQueue = effectQueue[1:] // Remove first effect
 
+	clearBoard()
 	display.ClearScreen()
 	fmt.Print(effect)
 
@@ -0,0 +1,23 @@
+//go:build !linux && !darwin && !freebsd
+
+package main
+
+import (
+	"errors"
+	"os"
+)
+
+// terminal stands in on platforms without termios, the client stays line based there
+type terminal struct{}
+
+func makeRaw() (*terminal, error) {
+	return nil, errors.New("raw terminal mode isn't supported on this platform")
+}
+
+func (t *terminal) restore() {}
+
+func (t *terminal) size() (width, height int, err error) {
+	return 0, 0, errors.New("raw terminal mode isn't supported on this platform")
+}
+
+func notifyResize(resized chan<- os.Signal) {}
@@ -0,0 +1,64 @@
+//go:build linux || darwin || freebsd
+
+package main
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"unsafe"
+)
+
+// terminal is stdin switched to raw mode, keys arrive one at a time and
+// unechoed until restore puts the old settings back
+type terminal struct {
+	fd  uintptr
+	old syscall.Termios
+}
+
+func ioctl(fd, request uintptr, arg unsafe.Pointer) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, request, uintptr(arg)); errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// makeRaw puts stdin into raw mode, the way cfmakeraw does, and fails when it isn't a terminal
+func makeRaw() (*terminal, error) {
+	t := &terminal{fd: os.Stdin.Fd()}
+	if err := ioctl(t.fd, ioctlGetTermios, unsafe.Pointer(&t.old)); err != nil {
+		return nil, err
+	}
+
+	raw := t.old
+	raw.Iflag &^= syscall.IGNBRK | syscall.BRKINT | syscall.PARMRK | syscall.ISTRIP | syscall.INLCR | syscall.IGNCR | syscall.ICRNL | syscall.IXON
+	raw.Oflag &^= syscall.OPOST
+	raw.Lflag &^= syscall.ECHO | syscall.ECHONL | syscall.ICANON | syscall.ISIG | syscall.IEXTEN
+	raw.Cflag &^= syscall.CSIZE | syscall.PARENB
+	raw.Cflag |= syscall.CS8
+	raw.Cc[syscall.VMIN] = 1
+	raw.Cc[syscall.VTIME] = 0
+
+	if err := ioctl(t.fd, ioctlSetTermios, unsafe.Pointer(&raw)); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (t *terminal) restore() {
+	ioctl(t.fd, ioctlSetTermios, unsafe.Pointer(&t.old))
+}
+
+// size is the terminal's width and height in cells
+func (t *terminal) size() (width, height int, err error) {
+	var ws struct{ Row, Col, Xpixel, Ypixel uint16 }
+	if err := ioctl(os.Stdout.Fd(), syscall.TIOCGWINSZ, unsafe.Pointer(&ws)); err != nil {
+		return 0, 0, err
+	}
+	return int(ws.Col), int(ws.Row), nil
+}
+
+// notifyResize sends on resized whenever the terminal window changes size
+func notifyResize(resized chan<- os.Signal) {
+	signal.Notify(resized, syscall.SIGWINCH)
+}
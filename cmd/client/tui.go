@@ -0,0 +1,628 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ahmaruff/go-fleet/internal/display"
+	"github.com/ahmaruff/go-fleet/internal/effects"
+	"github.com/ahmaruff/go-fleet/internal/game"
+	"github.com/ahmaruff/go-fleet/internal/protocol"
+)
+
+const (
+	effectTime  = 3 * time.Second // how long effect art covers the boards, as in the line client
+	logLimit    = 500             // message log lines kept for scrolling back
+	minLogLines = 3               // the log keeps at least this many lines on a short terminal
+	boardSpace  = "      "        // between the two boards
+)
+
+var lobbyHelp = []string{
+	"Type /ready if you're ready for war or /quit to exit",
+	"Playing a friend? /create-room gives you a code for them to /join",
+	"Say hello to the lobby with /say <message>",
+}
+
+// target is a cell on the opponent's board
+type target struct{ row, col int }
+
+// tui is the full-screen client: a status bar, both boards with a cursor on
+// the opponent's, a scrolling message log and an input line. Everything runs
+// on the goroutine in run, the connection and keyboard only feed it.
+type tui struct {
+	conn *connection
+	term *terminal
+	out  *bufio.Writer
+
+	width, height int
+	drawn         []string // what each screen line shows, only lines that change are redrawn
+
+	name   string // who the server knows us as, empty until /name or /login
+	view   *game.View
+	viewAt time.Time // when view arrived, to count its time left down
+	cursor target
+	marked []target // salvo targets picked with Space
+
+	effects     []string // effect art waiting its turn, the first one is on screen
+	effectTimer <-chan time.Time
+
+	log      []string
+	logLines int // log lines on screen, a page for PgUp and PgDn
+	scroll   int // lines scrolled back from the newest
+	input    []rune
+}
+
+// runTUI plays on conn full-screen until the player quits, leaving the terminal as it found it
+func runTUI(conn *connection, term *terminal, welcome protocol.Welcome) error {
+	t := &tui{conn: conn, term: term, out: bufio.NewWriter(os.Stdout)}
+
+	// the alternate screen keeps the shell's scrollback clean, lines are cut rather than wrapped
+	t.out.WriteString("\033[?1049h\033[?7l")
+	defer func() {
+		t.out.WriteString("\033[?7h\033[?1049l")
+		t.out.Flush()
+		term.restore()
+	}()
+
+	messages := make(chan protocol.Message, 64)
+	news := make(chan string, 16)
+	lost := make(chan error, 1)
+	conn.info = func(text string) { news <- text }
+	go func() {
+		lost <- conn.listen(func(m protocol.Message) { messages <- m })
+	}()
+
+	keys := make(chan key, 64)
+	go readKeys(os.Stdin, keys)
+
+	resized := make(chan os.Signal, 1)
+	notifyResize(resized)
+
+	// the clock in the status bar counts down between updates
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	t.addLog(fmt.Sprintf("[INFO] - Connected! (protocol v%d)", welcome.Version))
+	t.addLog("[INFO] - Type your name to play as a guest, /login <name> <password> if you have an account or /register <name> <password> to make one")
+	t.resize()
+
+	for {
+		select {
+		case k, ok := <-keys:
+			if !ok || !t.handleKey(k) {
+				return nil
+			}
+		case m := <-messages:
+			t.handleMessage(m)
+		case text := <-news:
+			t.addLog(text)
+		case err := <-lost:
+			return err
+		case <-resized:
+			t.resize()
+		case <-t.effectTimer:
+			t.nextEffect()
+		case <-ticker.C:
+		}
+
+		t.draw()
+	}
+}
+
+// handleKey acts on one key press, false means the player quit
+func (t *tui) handleKey(k key) bool {
+	switch k.kind {
+	case keyQuit:
+		return false
+
+	case keyRune:
+		if k.r == ' ' && len(t.input) == 0 && t.aiming() {
+			t.toggleMark()
+			break
+		}
+		t.input = append(t.input, k.r)
+
+	case keyBackspace:
+		if len(t.input) > 0 {
+			t.input = t.input[:len(t.input)-1]
+		}
+
+	case keyClearLine:
+		t.input = nil
+
+	case keyEscape:
+		if len(t.effects) > 0 {
+			t.skipEffects()
+			break
+		}
+		t.input, t.marked = nil, nil
+
+	case keyUp:
+		t.moveCursor(-1, 0)
+	case keyDown:
+		t.moveCursor(1, 0)
+	case keyLeft:
+		t.moveCursor(0, -1)
+	case keyRight:
+		t.moveCursor(0, 1)
+
+	case keyPageUp:
+		t.scroll += max(1, t.logLines-1)
+	case keyPageDown:
+		t.scroll = max(0, t.scroll-max(1, t.logLines-1))
+
+	case keyEnter:
+		if len(t.input) > 0 {
+			line := string(t.input)
+			t.input = nil
+			return t.submit(line)
+		}
+		t.fire()
+	}
+
+	return true
+}
+
+// submit sends a typed line. Before the server knows us, a plain name is a /name.
+func (t *tui) submit(line string) bool {
+	t.addLog("> " + line)
+
+	if line == "quit" || line == "/quit" || line == "/exit" {
+		return false
+	}
+
+	command, ok := protocol.ParseCommand(line)
+	if !ok {
+		return true
+	}
+
+	if t.name == "" && !strings.HasPrefix(command.Name, "/") {
+		command = protocol.Command{Name: "/name", Args: strings.Fields(line)}
+	}
+
+	t.send(command)
+	return true
+}
+
+func (t *tui) send(command protocol.Command) {
+	err := t.conn.send(command)
+	if errors.Is(err, errNotConnected) {
+		t.addLog("[INFO] - Not connected right now, try again once reconnected")
+		return
+	}
+
+	if err != nil {
+		t.addLog("[ERROR] - Failed to send message: " + err.Error())
+	}
+}
+
+// aiming reports whether there's a cursor on the opponent's board, in combat and not spectating
+func (t *tui) aiming() bool {
+	return t.view != nil && !t.view.Spectating && t.view.Phase == game.PhasePlaying
+}
+
+func (t *tui) moveCursor(rows, cols int) {
+	// the art is over the boards, moving on puts them back
+	t.skipEffects()
+
+	if !t.aiming() {
+		return
+	}
+
+	board := t.view.Opponent
+	t.cursor.row = min(max(t.cursor.row+rows, 0), board.Height-1)
+	t.cursor.col = min(max(t.cursor.col+cols, 0), board.Width-1)
+}
+
+// toggleMark picks or drops the cell under the cursor as a salvo target
+func (t *tui) toggleMark() {
+	if t.view.ShotsAllowed == 0 {
+		return
+	}
+
+	if i := slices.Index(t.marked, t.cursor); i >= 0 {
+		t.marked = slices.Delete(t.marked, i, i+1)
+		return
+	}
+
+	if len(t.marked) < t.view.ShotsAllowed {
+		t.marked = append(t.marked, t.cursor)
+	}
+}
+
+// fire shoots at the cursor, under Salvo rules it marks the cursor and
+// fires once every shot of the turn has a target
+func (t *tui) fire() {
+	if len(t.effects) > 0 {
+		// nobody fires blind, Enter only clears the art
+		t.skipEffects()
+		return
+	}
+
+	if !t.aiming() {
+		return
+	}
+
+	allowed := t.view.ShotsAllowed
+	if allowed == 0 {
+		t.send(protocol.Command{Name: "/fire", Args: []string{game.CellName(t.cursor.row, t.cursor.col)}})
+		return
+	}
+
+	if !slices.Contains(t.marked, t.cursor) && len(t.marked) < allowed {
+		t.marked = append(t.marked, t.cursor)
+	}
+
+	if len(t.marked) < allowed {
+		t.addLog(fmt.Sprintf("[SALVO] - %d of %d targets marked, Space marks the next one", len(t.marked), allowed))
+		return
+	}
+
+	var cells []string
+	for _, m := range t.marked {
+		cells = append(cells, game.CellName(m.row, m.col))
+	}
+	t.marked = nil
+
+	t.send(protocol.Command{Name: "/fire", Args: cells})
+}
+
+func (t *tui) handleMessage(m protocol.Message) {
+	switch msg := m.(type) {
+	case protocol.Effect:
+		t.showEffect(effects.GetEffect(msg.Name))
+
+	case protocol.State:
+		t.setView(msg.View)
+
+	case protocol.Session:
+		t.name = msg.Name
+
+	case protocol.Chat:
+		t.addLog(chatLine(msg))
+
+	case protocol.Error:
+		t.addLog(display.Red + "[ERROR] - " + msg.Text + display.Reset)
+
+	case protocol.Notice:
+		t.notice(msg)
+	}
+}
+
+func (t *tui) notice(msg protocol.Notice) {
+	switch msg.Tag {
+	case "GAME_RESET", "WATCH_END", "OPPONENT_DISCONNECTED":
+		// back to the lobby, any art still queued plays out first
+		t.view, t.marked = nil, nil
+
+	case "GAME_OVER":
+		t.addLog(display.Yellow + "[GAME_OVER] - " + msg.Text + display.Reset)
+		return
+	}
+
+	if msg.Text != "" {
+		t.addLog(fmt.Sprintf("[%s] - %s", msg.Tag, msg.Text))
+	}
+}
+
+func (t *tui) setView(v game.View) {
+	if t.view == nil || t.view.Phase != v.Phase {
+		t.marked = nil
+	}
+
+	t.view, t.viewAt = &v, time.Now()
+	t.cursor.row = max(min(t.cursor.row, v.Opponent.Height-1), 0)
+	t.cursor.col = max(min(t.cursor.col, v.Opponent.Width-1), 0)
+}
+
+func (t *tui) showEffect(art string) {
+	if art == "" {
+		return
+	}
+
+	t.effects = append(t.effects, art)
+	if len(t.effects) == 1 {
+		t.effectTimer = time.After(effectTime)
+	}
+}
+
+func (t *tui) nextEffect() {
+	t.effects, t.effectTimer = t.effects[1:], nil
+	if len(t.effects) > 0 {
+		t.effectTimer = time.After(effectTime)
+	}
+}
+
+func (t *tui) skipEffects() {
+	t.effects, t.effectTimer = nil, nil
+}
+
+// addLog appends to the message log, a reader scrolled back stays where they are
+func (t *tui) addLog(text string) {
+	for _, line := range strings.Split(text, "\n") {
+		t.log = append(t.log, line)
+		if t.scroll > 0 {
+			t.scroll += len(wrap(line, t.width))
+		}
+	}
+
+	if len(t.log) > logLimit {
+		t.log = t.log[len(t.log)-logLimit:]
+	}
+}
+
+// resize fits the screen to the terminal and draws it afresh
+func (t *tui) resize() {
+	width, height, err := t.term.size()
+	if err != nil || width == 0 || height == 0 {
+		width, height = 80, 24
+	}
+
+	t.width, t.height = width, height
+	t.drawn = nil
+	t.out.WriteString("\033[2J")
+	t.draw()
+}
+
+// draw brings the terminal up to date, rewriting only the lines that changed
+func (t *tui) draw() {
+	lines := t.screen()
+
+	for i, line := range lines {
+		if i < len(t.drawn) && t.drawn[i] == line {
+			continue
+		}
+		fmt.Fprintf(t.out, "\033[%d;1H%s\033[0m\033[K", i+1, line)
+	}
+
+	// anything left over from a taller screen
+	for i := len(lines); i < len(t.drawn); i++ {
+		fmt.Fprintf(t.out, "\033[%d;1H\033[K", i+1)
+	}
+	t.drawn = lines
+
+	// the terminal's own cursor waits at the end of the input line
+	fmt.Fprintf(t.out, "\033[%d;%dH", len(lines), utf8.RuneCountInString(lines[len(lines)-1])+1)
+	t.out.Flush()
+}
+
+// screen lays out every line: status bar, boards or art, message log and input
+func (t *tui) screen() []string {
+	top := []string{t.statusBar(), ""}
+
+	switch {
+	case len(t.effects) > 0:
+		top = append(top, strings.Split(t.effects[0], "\n")...)
+	case t.view != nil:
+		top = append(top, t.boards()...)
+	default:
+		top = append(top, t.lobby()...)
+	}
+	top = append(top, "")
+
+	// a short terminal loses the bottom of the boards before the log goes
+	t.logLines = max(t.height-len(top)-2, min(minLogLines, t.height-2), 1)
+	if len(top)+t.logLines+2 > t.height {
+		top = top[:max(t.height-t.logLines-2, 0)]
+	}
+
+	lines := append(top, t.logSeparator())
+	lines = append(lines, t.logWindow()...)
+	return append(lines, t.inputLine())
+}
+
+func (t *tui) statusBar() string {
+	parts := []string{"GO-FLEET"}
+
+	if t.name == "" {
+		parts = append(parts, "not signed in")
+	} else {
+		parts = append(parts, t.name)
+	}
+
+	v := t.view
+	if v == nil {
+		parts = append(parts, "lobby")
+	} else {
+		parts = append(parts, v.Own.Name+" vs "+v.Opponent.Name, string(v.Phase))
+
+		if v.Phase == game.PhasePlaying {
+			switch {
+			case v.Spectating && v.YourTurn:
+				parts = append(parts, v.Own.Name+"'s turn")
+			case v.Spectating:
+				parts = append(parts, v.Opponent.Name+"'s turn")
+			case v.YourTurn:
+				parts = append(parts, "YOUR TURN")
+			default:
+				parts = append(parts, "opponent's turn")
+			}
+		}
+
+		if v.Mode != "" && v.Mode != game.ModeClassic {
+			parts = append(parts, strings.ToUpper(string(v.Mode)))
+		}
+		if v.Charges != nil {
+			parts = append(parts, fmt.Sprintf("Radar %d Strike %d Torpedo %d", v.Charges.Radar, v.Charges.Strike, v.Charges.Torpedo))
+		}
+		if v.Series != nil {
+			parts = append(parts, fmt.Sprintf("Series %d-%d (Game %d, Bo%d)", v.Series.Wins, v.Series.OpponentWins, v.Series.Game, v.Series.BestOf))
+		}
+		if v.TimeLeft > 0 {
+			left := max(v.TimeLeft-int(time.Since(t.viewAt).Seconds()), 0)
+			parts = append(parts, fmt.Sprintf("%ds left", left))
+		}
+	}
+
+	bar := []rune(" " + strings.Join(parts, " | "))
+	if len(bar) > t.width {
+		bar = bar[:t.width]
+	}
+
+	return display.Reverse + string(bar) + strings.Repeat(" ", t.width-len(bar)) + display.Reset
+}
+
+// boards draws both boards side by side with the cursor on the opponent's, and what to do next under them
+func (t *tui) boards() []string {
+	v := t.view
+
+	ownTitle := fmt.Sprintf("Your Board (%d ships)", v.Own.ShipsLeft)
+	opponentTitle := fmt.Sprintf("Opponent's Board (%d ships)", v.Opponent.ShipsLeft)
+	if v.Spectating {
+		ownTitle = fmt.Sprintf("%s's Board (%d ships)", v.Own.Name, v.Own.ShipsLeft)
+		opponentTitle = fmt.Sprintf("%s's Board (%d ships)", v.Opponent.Name, v.Opponent.ShipsLeft)
+	}
+
+	own := display.RenderBoard(v.Own, nil)
+	opponent := display.RenderBoard(v.Opponent, t.markCell)
+
+	// the header line has no colours, so its length is the board's printed width
+	boardWidth := len(own[0])
+	column := max(boardWidth, utf8.RuneCountInString(ownTitle))
+
+	lines := []string{fmt.Sprintf("%-*s%s%s", column, ownTitle, boardSpace, opponentTitle)}
+	for i := range own {
+		lines = append(lines, own[i]+strings.Repeat(" ", column-boardWidth)+boardSpace+opponent[i])
+	}
+
+	return append(append(lines, ""), t.hint()...)
+}
+
+// markCell draws the cursor and salvo targets on the opponent's board
+func (t *tui) markCell(row, col int, cell string) string {
+	if !t.aiming() {
+		return cell
+	}
+
+	here := target{row, col}
+	if slices.Contains(t.marked, here) {
+		cell = display.Yellow + "*" + display.Reset
+	}
+	if here == t.cursor {
+		cell = display.Reverse + cell + display.Reset
+	}
+
+	return cell
+}
+
+// hint says what the player can do next
+func (t *tui) hint() []string {
+	v := t.view
+
+	switch {
+	case v.Spectating:
+		return []string{"Spectating - /unwatch to stop watching"}
+
+	case v.Phase.Placing() && len(v.Own.Unplaced) > 0:
+		ships := "Ships to place:"
+		for _, class := range v.Own.Unplaced {
+			ships += fmt.Sprintf(" %s(%d)", class.Name, class.Size)
+		}
+		return []string{ships, "Type /place Carrier A1 H (or V), /random places the rest, /unset A1 or /clear takes ships back"}
+
+	case v.Phase.Placing() && !v.Confirmed:
+		return []string{"Fleet complete! /confirm to lock it in, or /unset A1 and /clear to rearrange it"}
+
+	case v.Phase.Placing():
+		return []string{"Fleet locked in! Waiting for opponent to finish placement..."}
+
+	case v.Phase != game.PhasePlaying:
+		return nil
+	}
+
+	at := game.CellName(t.cursor.row, t.cursor.col)
+	lines := []string{"Arrows aim, Enter fires at " + at}
+	if v.ShotsAllowed > 0 {
+		lines = []string{fmt.Sprintf("Arrows aim, Space marks a target (%d of %d), Enter fires the salvo", len(t.marked), v.ShotsAllowed)}
+	}
+	if v.Charges != nil {
+		lines = append(lines, fmt.Sprintf("Weapons: /radar %s, /strike %s, /torpedo %s down", at, at, at))
+	}
+
+	return lines
+}
+
+func (t *tui) lobby() []string {
+	lines := append(strings.Split(effects.GetEffect("WELCOME"), "\n"), "")
+	if t.name == "" {
+		return append(lines, "Type your name to begin")
+	}
+	return append(lines, lobbyHelp...)
+}
+
+func (t *tui) logSeparator() string {
+	title := "--- Messages (PgUp/PgDn to scroll) "
+	if t.scroll > 0 {
+		title = fmt.Sprintf("--- Messages, %d lines back (PgDn for newer) ", t.scroll)
+	}
+	return title + strings.Repeat("-", max(t.width-utf8.RuneCountInString(title), 0))
+}
+
+// logWindow is the part of the log on screen, oldest at the top
+func (t *tui) logWindow() []string {
+	var wrapped []string
+	for _, line := range t.log {
+		wrapped = append(wrapped, wrap(line, t.width)...)
+	}
+
+	t.scroll = max(min(t.scroll, len(wrapped)-t.logLines), 0)
+	end := len(wrapped) - t.scroll
+	window := slices.Clone(wrapped[max(end-t.logLines, 0):end])
+
+	for len(window) < t.logLines {
+		window = append(window, "")
+	}
+	return window
+}
+
+// inputLine shows what's being typed, the end of it when it's too long to fit
+func (t *tui) inputLine() string {
+	input := t.input
+	if room := t.width - 3; room > 0 && len(input) > room {
+		input = input[len(input)-room:]
+	}
+	return "> " + string(input)
+}
+
+// wrap breaks line into pieces no wider than width, colour codes take no room
+func wrap(line string, width int) []string {
+	if width < 1 {
+		return []string{line}
+	}
+
+	var lines []string
+	var current strings.Builder
+	n := 0
+
+	for i := 0; i < len(line); {
+		if line[i] == 0x1b && i+1 < len(line) && line[i+1] == '[' {
+			end := i + 2
+			for end < len(line) && (line[end] < 0x40 || line[end] > 0x7e) {
+				end++
+			}
+			end = min(end+1, len(line))
+			current.WriteString(line[i:end])
+			i = end
+			continue
+		}
+
+		if n == width {
+			lines = append(lines, current.String())
+			current.Reset()
+			n = 0
+		}
+
+		_, size := utf8.DecodeRuneInString(line[i:])
+		current.WriteString(line[i : i+size])
+		i += size
+		n++
+	}
+
+	return append(lines, current.String())
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ahmaruff/go-fleet/internal/display"
+	"github.com/ahmaruff/go-fleet/internal/game"
+	"github.com/ahmaruff/go-fleet/internal/replay"
+)
+
+const controls = "[Enter] step  [b] back  [p] play/pause  [r] reveal fleets  [q] quit"
+
+// playback rebuilds the game up to any point of a recorded match
+type playback struct {
+	events []game.Event
+	pos    int // events applied so far, the start event included
+	game   *game.Game
+}
+
+func newPlayback(events []game.Event) (*playback, error) {
+	if len(events) == 0 {
+		return nil, fmt.Errorf("replay is empty")
+	}
+
+	p := &playback{events: events}
+	return p, p.seek(1)
+}
+
+func (p *playback) done() bool {
+	return p.pos >= len(p.events)
+}
+
+func (p *playback) step() error {
+	if p.done() {
+		return nil
+	}
+
+	if err := p.game.Apply(p.events[p.pos]); err != nil {
+		return err
+	}
+	p.pos++
+
+	return nil
+}
+
+// seek replays from scratch, games only move forward
+func (p *playback) seek(pos int) error {
+	g, err := game.NewGameFromHistory(p.events[0])
+	if err != nil {
+		return err
+	}
+
+	p.game = g
+	p.pos = 1
+
+	for p.pos < pos && !p.done() {
+		if err := p.step(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// describe turns the last applied event into a line for the status bar
+func (p *playback) describe() string {
+	e := p.events[p.pos-1]
+
+	name := func(n int) string {
+		if n == 2 {
+			return p.game.Player2.Name
+		}
+		return p.game.Player1.Name
+	}
+
+	switch e.Kind {
+	case game.EventStart:
+		return fmt.Sprintf("%s vs %s on a %dx%d board", e.Players[0], e.Players[1], e.Rules.Width, e.Rules.Height)
+	case game.EventPlace:
+		return fmt.Sprintf("%s places %s at %s (%s)", name(e.Player), e.Ship, e.Cell, e.Orientation)
+	case game.EventShot:
+		if e.Sunk != "" {
+			return fmt.Sprintf("%s fires at %s - %s, %s sunk!", name(e.Player), e.Cell, e.Outcome, e.Sunk)
+		}
+		return fmt.Sprintf("%s fires at %s - %s", name(e.Player), e.Cell, e.Outcome)
+	case game.EventSalvo:
+		return fmt.Sprintf("%s fires a salvo - %s", name(e.Player), describeShots(e))
+	case game.EventRadar:
+		return fmt.Sprintf("%s sweeps around %s with radar - %s", name(e.Player), e.Cell, e.Outcome)
+	case game.EventStrike:
+		return fmt.Sprintf("%s calls an airstrike on %s - %s", name(e.Player), e.Cell, describeShots(e))
+	case game.EventTorpedo:
+		return fmt.Sprintf("%s fires a torpedo from %s heading %s - %s", name(e.Player), e.Cell, e.Orientation, describeShots(e))
+	case game.EventSkip:
+		return name(e.Player) + " ran out of time, turn skipped"
+	case game.EventForfeit:
+		return name(e.Player) + " forfeits"
+	case game.EventPhase:
+		if e.Phase == game.PhaseFinished {
+			winner, _ := p.game.IsGameOver()
+			return fmt.Sprintf("Game over, %s wins!", name(winner))
+		}
+		return "Phase: " + string(e.Phase)
+	default:
+		return string(e.Kind)
+	}
+}
+
+// describeShots lists the cells of a batch of shots with their outcomes
+func describeShots(e game.Event) string {
+	if len(e.Cells) == 0 {
+		return "nothing left to hit"
+	}
+
+	shots := make([]string, len(e.Cells))
+	for i, cell := range e.Cells {
+		shots[i] = cell + " " + e.Outcomes[i]
+	}
+	return strings.Join(shots, ", ")
+}
+
+func main() {
+	reveal := flag.Bool("reveal", false, "Show both fleets instead of Player 1's view")
+	speed := flag.Duration("speed", time.Second, "Delay between events while playing")
+	autoplay := flag.Bool("play", false, "Start playing right away")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "Usage: replay [flags] <file.jsonl>")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	events, err := replay.Load(flag.Arg(0))
+	if err != nil {
+		log.Fatal("[ERROR] - ", err)
+	}
+
+	p, err := newPlayback(events)
+	if err != nil {
+		log.Fatal("[ERROR] - ", err)
+	}
+
+	input := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			input <- strings.ToLower(strings.TrimSpace(scanner.Text()))
+		}
+		close(input)
+	}()
+
+	playing := *autoplay
+
+	for {
+		display.ClearScreen()
+		if *reveal {
+			fmt.Print(display.RenderGameRevealedAsString(p.game))
+		} else {
+			fmt.Print(display.RenderGameAsString(p.game))
+		}
+
+		status := "PAUSED"
+		if playing {
+			status = "PLAYING"
+		}
+		fmt.Printf("\n[%s] Event %d/%d: %s\n", status, p.pos, len(p.events), p.describe())
+		fmt.Println(controls)
+
+		if p.done() {
+			playing = false
+			fmt.Println("End of replay")
+		}
+
+		var tick <-chan time.Time
+		if playing {
+			tick = time.After(*speed)
+		}
+
+		select {
+		case <-tick:
+			err = p.step()
+
+		case line, ok := <-input:
+			if !ok {
+				return
+			}
+
+			switch line {
+			case "":
+				playing = false
+				err = p.step()
+			case "b", "back":
+				playing = false
+				err = p.seek(p.pos - 1)
+			case "p", "play", "pause":
+				playing = !playing
+			case "r", "reveal":
+				*reveal = !*reveal
+			case "q", "quit", "/quit":
+				return
+			}
+		}
+
+		if err != nil {
+			log.Fatal("[ERROR] - Replay doesn't match the rules: ", err)
+		}
+	}
+}
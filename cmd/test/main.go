@@ -1,47 +1,58 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"os"
+
 	"github.com/ahmaruff/go-fleet/internal/display"
 	"github.com/ahmaruff/go-fleet/internal/game"
 )
 
 func main() {
-	b1 := game.Board{
-		Grid:      [10][10]int{},
-		ShipCount: 0,
+	clients := flag.Int("clients", 0, "Simulate this many concurrent clients against an in-process server")
+	flag.Parse()
+
+	if *clients > 0 {
+		if err := simulate(*clients); err != nil {
+			fmt.Println("[TEST] FAILED:", err)
+			os.Exit(1)
+		}
+		return
 	}
 
 	p1 := game.Player{
-		Name:  "Player 1",
-		Board: &b1,
-	}
-
-	b2 := game.Board{
-		Grid:      [10][10]int{},
-		ShipCount: 0,
+		Name: "Player 1",
 	}
 
 	p2 := game.Player{
-		Name:  "Player 2",
-		Board: &b2,
+		Name: "Player 2",
 	}
 
+	// NewGame hands each player a fresh 10x10 board
 	g := game.NewGame(&p1, &p2)
 
-	g.PlaceShipForPlayer("B1")
-	g.PlaceShipForPlayer("C2")
-	g.PlaceShipForPlayer("D3")
-	g.PlaceShipForPlayer("E4")
-	g.PlaceShipForPlayer("F5")
-
-	g.SwitchPlayer()
-
-	g.PlaceShipForPlayer("A5")
-	g.PlaceShipForPlayer("B4")
-	g.PlaceShipForPlayer("C3")
-	g.PlaceShipForPlayer("D2")
-	g.PlaceShipForPlayer("E1")
+	g.PlaceShipForPlayer(&p1, "Carrier", "A1", "H")
+	g.PlaceShipForPlayer(&p1, "Battleship", "C3", "V")
+	g.PlaceShipForPlayer(&p1, "Cruiser", "E8", "H")
+	g.PlaceShipForPlayer(&p1, "Submarine", "J5", "V")
+	g.PlaceShipForPlayer(&p1, "Destroyer", "F10", "H")
+
+	g.PlaceShipForPlayer(&p2, "Carrier", "B2", "V")
+	g.PlaceShipForPlayer(&p2, "Battleship", "D9", "H")
+	g.PlaceShipForPlayer(&p2, "Cruiser", "F1", "H")
+	g.PlaceShipForPlayer(&p2, "Submarine", "H4", "V")
+	g.PlaceShipForPlayer(&p2, "Destroyer", "D5", "H")
+
+	g.ConfirmFleet(&p1)
+	g.ConfirmFleet(&p2)
+
+	g.FireAtOpponent(&p1, "B3")
+	g.FireAtOpponent(&p2, "A1")
+	g.FireAtOpponent(&p1, "D5")
+	g.FireAtOpponent(&p2, "B1")
+	result, _ := g.FireAtOpponent(&p1, "E5")
+	fmt.Println("Player 1 fires at E5 - Result:", result.Outcome, result.Ship.Class.Name)
 
 	fmt.Println("Testing display...")
 	display.RenderGame(g)
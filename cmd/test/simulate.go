@@ -0,0 +1,205 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ahmaruff/go-fleet/internal/game"
+	"github.com/ahmaruff/go-fleet/internal/protocol"
+	"github.com/ahmaruff/go-fleet/internal/server"
+)
+
+// simulate starts an in-process server and plays full matches with n scripted
+// clients at once. Run it under the race detector:
+//
+//	go run -race ./cmd/test --clients 200
+func simulate(n int) error {
+	if n < 2 || n%2 != 0 {
+		return fmt.Errorf("need an even number of clients, got %d", n)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+
+	srv := server.New(server.Config{Rules: game.DefaultRules(), Quiet: true})
+	defer srv.Close()
+	go srv.Serve(listener)
+
+	start := time.Now()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			if err := runSimulatedClient(listener.Addr().String(), id); err != nil {
+				errs <- fmt.Errorf("client %d: %w", id, err)
+			}
+		}(i)
+	}
+
+	wg.Wait()
+	close(errs)
+
+	failed := 0
+	for err := range errs {
+		fmt.Println("[TEST]", err)
+		failed++
+	}
+
+	fmt.Printf("[TEST] %d clients, %d matches, %d failures in %s\n", n, n/2, failed, time.Since(start).Round(time.Millisecond))
+	if failed > 0 {
+		return fmt.Errorf("%d clients failed", failed)
+	}
+
+	return nil
+}
+
+type simClient struct {
+	encoder  *protocol.Encoder
+	messages chan protocol.Message
+}
+
+func (s *simClient) send(line string) error {
+	command, _ := protocol.ParseCommand(line)
+	return s.encoder.Encode(command)
+}
+
+// expect waits for a notice tagged with one of tags, skipping everything else.
+// The tag "ERROR" matches any error reply.
+func (s *simClient) expect(timeout time.Duration, tags ...string) (string, error) {
+	deadline := time.After(timeout)
+	for {
+		select {
+		case m, ok := <-s.messages:
+			if !ok {
+				return "", fmt.Errorf("connection closed while waiting for %v", tags)
+			}
+
+			tag := ""
+			switch msg := m.(type) {
+			case protocol.Notice:
+				tag = msg.Tag
+			case protocol.Error:
+				tag = "ERROR"
+			case protocol.State:
+				tag = "STATE"
+			}
+
+			if slices.Contains(tags, tag) {
+				return tag, nil
+			}
+		case <-deadline:
+			return "", fmt.Errorf("timed out waiting for %v", tags)
+		}
+	}
+}
+
+func runSimulatedClient(address string, id int) error {
+	conn, err := net.Dial("tcp", address)
+	if err != nil {
+		return err
+	}
+
+	s := &simClient{encoder: protocol.NewEncoder(conn), messages: make(chan protocol.Message, 64)}
+	decoder := protocol.NewDecoder(conn)
+
+	if _, err := protocol.ClientHandshake(s.encoder, decoder, ""); err != nil {
+		conn.Close()
+		return err
+	}
+
+	defer func() {
+		conn.Close()
+		for range s.messages {
+		}
+	}()
+
+	go func() {
+		defer close(s.messages)
+		for {
+			m, err := decoder.Decode()
+			if err != nil {
+				return
+			}
+			s.messages <- m
+		}
+	}()
+
+	// each command waits for its reply before the next one goes out
+	if err := s.send(fmt.Sprintf("/name bot-%d", id)); err != nil {
+		return err
+	}
+	if _, err := s.expect(5*time.Second, "NAME_SET"); err != nil {
+		return err
+	}
+
+	if err := s.send("/ready"); err != nil {
+		return err
+	}
+	if _, err := s.expect(30*time.Second, "GAME_START"); err != nil {
+		return err
+	}
+
+	for i, class := range game.Fleet {
+		if err := s.send(fmt.Sprintf("/place %s A%d H", class.Name, i*2+1)); err != nil {
+			return err
+		}
+
+		if _, err := s.expect(5*time.Second, "SHIP_PLACED"); err != nil {
+			return err
+		}
+	}
+
+	// combat starts once the second player locks in
+	if err := s.send("/confirm"); err != nil {
+		return err
+	}
+	if _, err := s.expect(5*time.Second, "FLEET_CONFIRMED"); err != nil {
+		return err
+	}
+	if _, err := s.expect(30*time.Second, "COMBAT_START"); err != nil {
+		return err
+	}
+
+	for row := 0; row < game.DefaultBoardSize; row++ {
+		for col := 0; col < game.DefaultBoardSize; col++ {
+			cell := game.CellName(row, col)
+			for {
+				if err := s.send("/fire " + cell); err != nil {
+					return err
+				}
+
+				tag, err := s.expect(30*time.Second, "SHOT_RESULT", "ERROR", "GAME_OVER", "OPPONENT_DISCONNECTED")
+				if err != nil {
+					return err
+				}
+
+				if tag == "GAME_OVER" {
+					return nil
+				}
+				if tag == "OPPONENT_DISCONNECTED" {
+					return fmt.Errorf("opponent disconnected")
+				}
+				if tag == "SHOT_RESULT" {
+					break
+				}
+
+				// not our turn yet, wait for the opponent's shot to land
+				tag, err = s.expect(100*time.Millisecond, "STATE", "GAME_OVER")
+				if err == nil && tag == "GAME_OVER" {
+					return nil
+				}
+			}
+		}
+	}
+
+	return fmt.Errorf("ran out of cells without a winner")
+}
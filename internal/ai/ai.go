@@ -0,0 +1,277 @@
+package ai
+
+import (
+	"errors"
+	"math/rand/v2"
+	"strings"
+
+	"github.com/ahmaruff/go-fleet/internal/game"
+)
+
+type Level string
+
+const (
+	Easy   Level = "easy"
+	Medium Level = "medium"
+	Hard   Level = "hard"
+)
+
+func ParseLevel(s string) (Level, error) {
+	switch Level(strings.ToLower(strings.TrimSpace(s))) {
+	case Easy:
+		return Easy, nil
+	case Medium, "":
+		return Medium, nil
+	case Hard:
+		return Hard, nil
+	default:
+		return "", errors.New("unknown difficulty: expected easy, medium or hard")
+	}
+}
+
+// Strategy picks the next shot from the opponent's board as any player sees it:
+// misses, hits and sunk ships, never the hidden fleet
+type Strategy interface {
+	NextShot(opponent game.BoardView) (row, col int)
+}
+
+func New(level Level, r *rand.Rand) Strategy {
+	switch level {
+	case Easy:
+		return &easy{r: r}
+	case Hard:
+		return &hard{r: r}
+	default:
+		return &medium{r: r}
+	}
+}
+
+// easy fires at any cell it hasn't tried yet
+type easy struct {
+	r *rand.Rand
+}
+
+func (s *easy) NextShot(opponent game.BoardView) (int, int) {
+	return randomCell(s.r, untried(opponent))
+}
+
+// medium hunts on a checkerboard, then targets around hits until the ship sinks
+type medium struct {
+	r *rand.Rand
+}
+
+func (s *medium) NextShot(opponent game.BoardView) (int, int) {
+	if targets := targetCells(opponent); len(targets) > 0 {
+		return randomCell(s.r, targets)
+	}
+
+	// the smallest ship is 2 long, so every ship crosses a checkerboard square
+	var parity [][2]int
+	for _, cell := range untried(opponent) {
+		if (cell[0]+cell[1])%2 == 0 {
+			parity = append(parity, cell)
+		}
+	}
+
+	if len(parity) > 0 {
+		return randomCell(s.r, parity)
+	}
+
+	return randomCell(s.r, untried(opponent))
+}
+
+// hard scores every untried cell by how many placements of the remaining ships could cover it
+type hard struct {
+	r *rand.Rand
+}
+
+func (s *hard) NextShot(opponent game.BoardView) (int, int) {
+	density := Density(opponent)
+
+	best := -1
+	var candidates [][2]int
+	for _, cell := range untried(opponent) {
+		score := density[cell[0]][cell[1]]
+		if score > best {
+			best = score
+			candidates = candidates[:0]
+		}
+		if score == best {
+			candidates = append(candidates, cell)
+		}
+	}
+
+	return randomCell(s.r, candidates)
+}
+
+// placements covering an unresolved hit are this many times likelier than blind ones
+const hitWeight = 50
+
+// Density counts, for every cell, the placements of still-afloat ships that fit
+// what's known about the board. Placements through unresolved hits weigh more.
+func Density(opponent game.BoardView) [][]int {
+	density := make([][]int, opponent.Height)
+	for row := range density {
+		density[row] = make([]int, opponent.Width)
+	}
+
+	blocked := func(row, col int) bool {
+		if opponent.Grid[row][col] == 2 {
+			return true
+		}
+		_, sunk := opponent.ShipAt(row, col)
+		return sunk
+	}
+
+	for _, class := range afloat(opponent) {
+		for _, orientation := range []game.Orientation{game.Horizontal, game.Vertical} {
+			for row := 0; row < opponent.Height; row++ {
+				for col := 0; col < opponent.Width; col++ {
+					ship := game.Ship{Class: class, Row: row, Col: col, Orientation: orientation}
+
+					fits := true
+					hits := 0
+					for _, cell := range ship.Cells() {
+						if cell[0] >= opponent.Height || cell[1] >= opponent.Width || blocked(cell[0], cell[1]) {
+							fits = false
+							break
+						}
+						if opponent.Grid[cell[0]][cell[1]] == 3 {
+							hits++
+						}
+					}
+
+					if !fits {
+						continue
+					}
+
+					weight := 1 + hits*hitWeight
+					for _, cell := range ship.Cells() {
+						density[cell[0]][cell[1]] += weight
+					}
+				}
+			}
+		}
+	}
+
+	return density
+}
+
+// afloat lists the ship classes the opponent hasn't lost yet
+func afloat(opponent game.BoardView) []game.ShipClass {
+	var classes []game.ShipClass
+	for _, class := range game.Fleet {
+		sunk := false
+		for _, ship := range opponent.Ships {
+			if ship.Class.Name == class.Name {
+				sunk = true
+				break
+			}
+		}
+
+		if !sunk {
+			classes = append(classes, class)
+		}
+	}
+
+	return classes
+}
+
+func untried(opponent game.BoardView) [][2]int {
+	var cells [][2]int
+	for row := range opponent.Grid {
+		for col, cell := range opponent.Grid[row] {
+			if cell == 0 {
+				cells = append(cells, [2]int{row, col})
+			}
+		}
+	}
+
+	return cells
+}
+
+// openHits are hits on ships that haven't sunk yet
+func openHits(opponent game.BoardView) [][2]int {
+	var cells [][2]int
+	for row := range opponent.Grid {
+		for col, cell := range opponent.Grid[row] {
+			if cell != 3 {
+				continue
+			}
+			if _, sunk := opponent.ShipAt(row, col); !sunk {
+				cells = append(cells, [2]int{row, col})
+			}
+		}
+	}
+
+	return cells
+}
+
+// targetCells are the untried neighbours of open hits, extending a line of hits first
+func targetCells(opponent game.BoardView) [][2]int {
+	hits := openHits(opponent)
+	if len(hits) == 0 {
+		return nil
+	}
+
+	isHit := make(map[[2]int]bool, len(hits))
+	for _, hit := range hits {
+		isHit[hit] = true
+	}
+
+	isOpen := func(row, col int) bool {
+		return row >= 0 && row < opponent.Height && col >= 0 && col < opponent.Width && opponent.Grid[row][col] == 0
+	}
+
+	var inLine, around [][2]int
+	for _, hit := range hits {
+		for _, dir := range [][2]int{{0, 1}, {0, -1}, {1, 0}, {-1, 0}} {
+			next := [2]int{hit[0] + dir[0], hit[1] + dir[1]}
+			if !isOpen(next[0], next[1]) {
+				continue
+			}
+
+			around = append(around, next)
+
+			// a hit on the other side means we're walking along a ship
+			behind := [2]int{hit[0] - dir[0], hit[1] - dir[1]}
+			if isHit[behind] {
+				inLine = append(inLine, next)
+			}
+		}
+	}
+
+	if len(inLine) > 0 {
+		return inLine
+	}
+
+	return around
+}
+
+func randomCell(r *rand.Rand, cells [][2]int) (int, int) {
+	if len(cells) == 0 {
+		return 0, 0
+	}
+
+	cell := cells[r.IntN(len(cells))]
+	return cell[0], cell[1]
+}
+
+// Volley picks n different cells for a salvo. Cells already picked count as
+// misses for the next pick, so a volley spreads out instead of stacking up.
+func Volley(s Strategy, opponent game.BoardView, n int) [][2]int {
+	view := opponent
+	view.Grid = make([][]int, len(opponent.Grid))
+	for row := range opponent.Grid {
+		view.Grid[row] = append([]int(nil), opponent.Grid[row]...)
+	}
+
+	var cells [][2]int
+	for len(cells) < n && len(untried(view)) > 0 {
+		row, col := s.NextShot(view)
+		cells = append(cells, [2]int{row, col})
+		view.Grid[row][col] = 2
+	}
+
+	return cells
+}
@@ -13,6 +13,8 @@ const (
 	Green  string = "\033[32m"
 	Red    string = "\033[31m"
 	Yellow string = "\033[33m"
+
+	Reverse string = "\033[7m" // swaps a cell's colours, for a cursor
 )
 
 func ClearScreen() {
@@ -34,156 +36,229 @@ func ConvertCellToChar(cellValue int) string {
 	}
 }
 
-func RenderGame(g *game.Game) {
-	ClearScreen()
-
-	isMyTurn := (g.CurrPlayer == 1)
-	turnText := "Opponent's Turn"
-	if isMyTurn {
-
-		turnText = "Your Turn"
+// ShipSegmentChar draws one segment of a ship, so a horizontal carrier reads <===> and a vertical one ^|||v
+func ShipSegmentChar(ship *game.Ship, row, col int) string {
+	segment := ship.Segment(row, col)
+	last := ship.Class.Size - 1
+
+	if ship.Orientation == game.Vertical {
+		switch segment {
+		case 0:
+			return Green + "^" + Reset
+		case last:
+			return Green + "v" + Reset
+		default:
+			return Green + "|" + Reset
+		}
 	}
 
-	// Game header
-	fmt.Println("===================================== GO-FLEET ==============================")
-
-	if g.Phase == "PLAYING" {
-		fmt.Printf("Player: %s vs %s | Phase: %s | Current Turn: %s\n",
-			g.Player1.Name, g.Player2.Name, g.Phase, turnText)
-	} else {
-		fmt.Printf("Player: %s vs %s | Phase: %s\n",
-			g.Player1.Name, g.Player2.Name, g.Phase)
+	switch segment {
+	case 0:
+		return Green + "<" + Reset
+	case last:
+		return Green + ">" + Reset
+	default:
+		return Green + "=" + Reset
 	}
+}
 
-	fmt.Printf("Player: %s vs %s | Phase: %s | Current Turn: %s\n",
-		g.Player1.Name, g.Player2.Name, g.Phase, turnText)
-	fmt.Println("=============================================================================")
+func renderOwnCell(b game.BoardView, row, col int) string {
+	if b.Grid[row][col] == 1 {
+		if ship, ok := b.ShipAt(row, col); ok {
+			return ShipSegmentChar(ship, row, col)
+		}
+	}
 
-	// legends
-	fmt.Printf(Blue + "~" + Reset + " = Water | " + Green + "S" + Reset + " = Ship | " + Red + "X" + Reset + " = Hit | " + Yellow + "O" + Reset + " = Miss\n")
+	return ConvertCellToChar(b.Grid[row][col])
+}
 
-	fmt.Println()
+// spacing between the two boards rendered side by side
+const boardGap = "                     "
 
-	fmt.Printf("Your Remaining Ships: %d\n", g.Player1.Board.ShipCount)
-	fmt.Printf("Opponent's Remaining Ships: %d\n", g.Player2.Board.ShipCount)
+// boardWidth is the printed width of one board: row label plus " c" per column
+func boardWidth(width int) int {
+	return 2 + 2*width
+}
 
-	fmt.Println("-----------------------------------------------------------------------------")
-	fmt.Println()
+// columnHeader prints the column letters lined up with the cells, e.g. "   A B C"
+func columnHeader(width int) string {
+	var header strings.Builder
+	header.WriteString("  ")
+	for col := 0; col < width; col++ {
+		header.WriteString(" " + game.ColumnName(col))
+	}
 
-	// Board headers
-	fmt.Println("Your Board:                               Opponent's Board:")
-	fmt.Println("   A B C D E F G H I J                        A B C D E F G H I J")
-
-	// Render both boards side by side
-	for row := 0; row < 10; row++ {
-		// Your board (left side)
-		fmt.Printf("%2d", row+1)
-		for col := 0; col < 10; col++ {
-			char := ConvertCellToChar(g.Player1.Board.Grid[row][col])
-			fmt.Printf(" %s", char) // Space before each character
-		}
+	return header.String()
+}
 
-		// Spacing between boards
-		fmt.Print("                     ")
-
-		// Opponent board (right side)
-		fmt.Printf("%2d", row+1)
-		for col := 0; col < 10; col++ {
-			cellValue := g.Player2.Board.Grid[row][col]
-			if cellValue == 2 || cellValue == 3 { // only render when HIT/MISS
-				char := ConvertCellToChar(cellValue)
-				fmt.Printf(" %s", char)
-			} else {
-				fmt.Printf(Blue + " ~" + Reset) // Consistent spacing
+// RenderBoard draws one board as lines, the column header first and then a
+// line per row. mark may dress up a drawn cell, e.g. to put a cursor on it,
+// or be nil.
+func RenderBoard(b game.BoardView, mark func(row, col int, cell string) string) []string {
+	lines := []string{columnHeader(b.Width)}
+
+	for row := 0; row < b.Height; row++ {
+		var line strings.Builder
+		line.WriteString(fmt.Sprintf("%2d", row+1))
+		for col := 0; col < b.Width; col++ {
+			// a fogged view never holds unhit ship cells, a revealed one draws them like our own
+			cell := renderOwnCell(b, row, col)
+			if mark != nil {
+				cell = mark(row, col, cell)
 			}
+			line.WriteString(" " + cell) // Space before each character
 		}
-		fmt.Println()
+		lines = append(lines, line.String())
 	}
 
-	fmt.Println()
-
-	if g.Phase == "PLACING" {
-		fmt.Println("Command: /set A1 — place your ship at A1")
-	}
+	return lines
+}
 
-	if g.Phase == "PLAYING" {
-		fmt.Println("Command: /fire B2 — fire at B2")
-	}
+func RenderGame(g *game.Game) {
+	ClearScreen()
+	fmt.Print(RenderGameAsString(g))
 }
 
+// RenderGameAsString draws the game as Player1 sees it
 func RenderGameAsString(g *game.Game) string {
-	isMyTurn := (g.CurrPlayer == 1)
+	return RenderView(g.ViewFor(g.Player1))
+}
+
+// RenderGameRevealedAsString draws the game with both fleets in plain sight, for replays
+func RenderGameRevealedAsString(g *game.Game) string {
+	return RenderView(g.RevealedView())
+}
+
+// Header is the line under the title bar: who's playing, the phase, whose
+// turn it is and the time left on the clock
+func Header(v game.View) string {
 	turnText := "Opponent's Turn"
-	if isMyTurn {
+	if v.YourTurn {
 
 		turnText = "Your Turn"
 	}
 
-	var output strings.Builder
+	if v.Spectating {
+		// nobody's side, name both players
+		turnText = v.Opponent.Name + "'s Turn"
+		if v.YourTurn {
+			turnText = v.Own.Name + "'s Turn"
+		}
+	}
 
-	// Game header
-	output.WriteString("============================== GO-FLEET ==============================\n")
+	timeText := ""
+	switch v.Mode {
+	case game.ModeSalvo:
+		timeText = " | Mode: SALVO"
+	case game.ModeAdvanced:
+		timeText = " | Mode: ADVANCED"
+	}
+	if v.Charges != nil {
+		timeText += fmt.Sprintf(" | Radar: %d Strike: %d Torpedo: %d", v.Charges.Radar, v.Charges.Strike, v.Charges.Torpedo)
+	}
+	if v.Series != nil {
+		timeText += fmt.Sprintf(" | Series: %d-%d (Game %d, Bo%d)", v.Series.Wins, v.Series.OpponentWins, v.Series.Game, v.Series.BestOf)
+	}
+	if v.TimeLeft > 0 {
+		timeText += fmt.Sprintf(" | Time Left: %ds", v.TimeLeft)
+	}
+
+	if v.Phase == game.PhasePlaying {
+		return fmt.Sprintf("Player: %s vs %s | Phase: %s | Current Turn: %s%s",
+			v.Own.Name, v.Opponent.Name, v.Phase, turnText, timeText)
+	}
+	return fmt.Sprintf("Player: %s vs %s | Phase: %s%s",
+		v.Own.Name, v.Opponent.Name, v.Phase, timeText)
+}
 
-	if g.Phase == "PLAYING" {
-		output.WriteString(fmt.Sprintf("Player: %s vs %s | Phase: %s | Current Turn: %s\n",
-			g.Player1.Name, g.Player2.Name, g.Phase, turnText))
-	} else {
-		output.WriteString(fmt.Sprintf("Player: %s vs %s | Phase: %s\n",
-			g.Player1.Name, g.Player2.Name, g.Phase))
+// RenderView draws a per-player snapshot: own board on the left, opponent on the right
+func RenderView(v game.View) string {
+	ownLabel, opponentLabel := "Your", "Opponent's"
+	if v.Spectating {
+		ownLabel, opponentLabel = v.Own.Name+"'s", v.Opponent.Name+"'s"
 	}
 
+	var output strings.Builder
+
+	// Game header
+	output.WriteString("============================== GO-FLEET ==============================\n")
+	output.WriteString(Header(v) + "\n")
 	output.WriteString("======================================================================\n")
 
 	// legends
-	output.WriteString(Blue + "~" + Reset + " = Water | " + Green + "S" + Reset + " = Ship | " + Red + "X" + Reset + " = Hit | " + Yellow + "O" + Reset + " = Miss\n")
+	output.WriteString(Blue + "~" + Reset + " = Water | " + Green + "<=>" + Reset + " = Ship | " + Red + "X" + Reset + " = Hit | " + Yellow + "O" + Reset + " = Miss\n")
 
 	output.WriteString("\n")
 
-	output.WriteString(fmt.Sprintf("Your Remaining Ships: %d\n", g.Player1.Board.ShipCount))
-	output.WriteString(fmt.Sprintf("Opponent's Remaining Ships: %d\n", g.Player2.Board.ShipCount))
+	output.WriteString(fmt.Sprintf("%s Remaining Ships: %d\n", ownLabel, v.Own.ShipsLeft))
+	output.WriteString(fmt.Sprintf("%s Remaining Ships: %d\n", opponentLabel, v.Opponent.ShipsLeft))
 
 	output.WriteString("----------------------------------------------------------------------\n\n")
 
+	width := v.Own.Width
+
 	// Board headers
-	output.WriteString("Your Board:                               Opponent's Board:\n")
-	output.WriteString("   A B C D E F G H I J                        A B C D E F G H I J\n")
-
-	// Render both boards side by side
-	for row := 0; row < 10; row++ {
-		// Your board (left side)
-		output.WriteString(fmt.Sprintf("%2d", row+1))
-		for col := 0; col < 10; col++ {
-			char := ConvertCellToChar(g.Player1.Board.Grid[row][col])
-			output.WriteString(fmt.Sprintf(" %s", char)) // Space before each character
-		}
+	output.WriteString(fmt.Sprintf("%-*s%s\n", boardWidth(width)+len(boardGap), ownLabel+" Board:", opponentLabel+" Board:"))
 
-		// Spacing between boards
-		output.WriteString("                     ")
+	// Render both boards side by side, yours on the left
+	own, opponent := RenderBoard(v.Own, nil), RenderBoard(v.Opponent, nil)
+	for i := range own {
+		output.WriteString(own[i] + boardGap + opponent[i] + "\n")
+	}
 
-		// Opponent board (right side)
-		output.WriteString(fmt.Sprintf("%2d", row+1))
-		for col := 0; col < 10; col++ {
-			cellValue := g.Player2.Board.Grid[row][col]
-			if cellValue == 2 || cellValue == 3 { // only render when HIT/MISS
-				char := ConvertCellToChar(cellValue)
+	output.WriteString("\n")
 
-				output.WriteString(fmt.Sprintf(" %s", char)) // Space before each character
-			} else {
-				output.WriteString(Blue + " ~" + Reset) // Space before each character
-			}
+	if v.Spectating {
+		if v.Phase == game.PhasePlaying && v.ShotsAllowed > 0 {
+			output.WriteString(fmt.Sprintf("Salvo: %d shots this turn\n", v.ShotsAllowed))
 		}
-		output.WriteString("\n")
+		output.WriteString("Spectating - /unwatch to stop watching\n")
+		return output.String()
 	}
 
-	output.WriteString("\n")
+	if v.Phase.Placing() {
+		unplaced := v.Own.Unplaced
+		switch {
+		case len(unplaced) > 0:
+			output.WriteString("Ships to place:")
+			for _, class := range unplaced {
+				output.WriteString(fmt.Sprintf(" %s(%d)", class.Name, class.Size))
+			}
+			output.WriteString("\n")
+			output.WriteString("Command: /place Carrier A1 H — place your carrier at A1, horizontal (H) or vertical (V)\n")
+			output.WriteString("         /random places the rest, /unset A1 or /clear takes ships back\n")
+		case !v.Confirmed:
+			output.WriteString("Fleet complete! /confirm to lock it in, or /unset A1 and /clear to rearrange it\n")
+		default:
+			output.WriteString("Fleet locked in! Waiting for opponent to finish placement...\n")
+		}
 
-	if g.Phase == "PLACING" {
-		output.WriteString("Command: /set A1 — place your ship at A1\n")
+		if v.OpponentConfirmed && !v.Confirmed {
+			output.WriteString("Your opponent has locked in their fleet\n")
+		}
 	}
 
-	if g.Phase == "PLAYING" {
-		output.WriteString("Command: /fire B2 — fire at B2\n")
+	if v.Phase == game.PhasePlaying {
+		if v.ShotsAllowed > 0 {
+			output.WriteString(fmt.Sprintf("Salvo: %d shots this turn, one per ship afloat\n", v.ShotsAllowed))
+			output.WriteString("Command: " + salvoExample(v.ShotsAllowed) + " — fire them all at once\n")
+		} else {
+			output.WriteString("Command: /fire B2 — fire at B2\n")
+		}
+
+		if v.Charges != nil {
+			output.WriteString("Weapons: /radar C3 — sweep C3 and around it, /strike C3 — hit B3 to D3, /torpedo C1 down — run until it hits\n")
+		}
 	}
 
 	return output.String()
 }
+
+// salvoExample spells out a /fire command with n shots, e.g. /fire A1 B2 C3
+func salvoExample(n int) string {
+	example := "/fire"
+	for i := 0; i < n; i++ {
+		example += " " + game.CellName(i, i)
+	}
+	return example
+}
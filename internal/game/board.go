@@ -1,5 +1,12 @@
 package game
 
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+)
+
 // GRID CELL STATE ----
 
 // 0 = Empty water (not fired upon)
@@ -8,49 +15,180 @@ package game
 // 3 = Ship hit
 
 type Board struct {
-	Grid      [10][10]int
+	Width  int
+	Height int
+	Grid   [][]int // Grid[row][col]
+	// ShipIDs maps every cell to the ship covering it: 0 = none, n = Ships[n-1]
+	ShipIDs   [][]int
+	Ships     []Ship
 	ShipCount int
 }
 
-func (b *Board) PlaceShip(row, col int) bool {
-	if row < 0 || col < 0 || row > 9 || col > 9 {
-		return false // Invalid coordinates
+func NewBoard(width, height int) *Board {
+	b := Board{
+		Width:   width,
+		Height:  height,
+		Grid:    make([][]int, height),
+		ShipIDs: make([][]int, height),
+	}
+
+	for row := 0; row < height; row++ {
+		b.Grid[row] = make([]int, width)
+		b.ShipIDs[row] = make([]int, width)
+	}
+
+	return &b
+}
+
+type ShotOutcome int
+
+const (
+	Miss ShotOutcome = iota
+	Hit
+	Sunk
+)
+
+func (o ShotOutcome) String() string {
+	switch o {
+	case Hit:
+		return "HIT"
+	case Sunk:
+		return "SUNK"
+	default:
+		return "MISS"
+	}
+}
+
+type ShotResult struct {
+	Row     int
+	Col     int
+	Outcome ShotOutcome
+	Ship    *Ship // nil on a miss
+}
+
+func (b *Board) PlaceShip(class ShipClass, row, col int, orientation Orientation) error {
+	if b.ShipCount >= len(Fleet) {
+		return ErrFleetComplete
+	}
+
+	for _, ship := range b.Ships {
+		if ship.Class.Name == class.Name {
+			return errors.New(class.Name + " is already placed")
+		}
+	}
+
+	ship := Ship{
+		Class:       class,
+		Row:         row,
+		Col:         col,
+		Orientation: orientation,
 	}
 
-	if b.ShipCount >= 5 {
-		return false
+	for _, cell := range ship.Cells() {
+		if !b.IsValidPosition(cell[0], cell[1]) {
+			return fmt.Errorf("%s doesn't fit on the board there: %w", class.Name, ErrOutOfBounds)
+		}
+
+		if b.Grid[cell[0]][cell[1]] != 0 {
+			return errors.New(class.Name + " overlaps another ship")
+		}
 	}
 
-	if b.Grid[row][col] != 0 {
-		return false // Cell already has something
+	b.Ships = append(b.Ships, ship)
+	for _, cell := range ship.Cells() {
+		b.Grid[cell[0]][cell[1]] = 1
+		b.ShipIDs[cell[0]][cell[1]] = len(b.Ships)
 	}
 
-	b.Grid[row][col] = 1
 	b.ShipCount += 1
 
-	return true
+	return nil
 }
 
-func (b *Board) Fire(row, col int) int {
-	fireMap := map[int]int{
-		0: 2,
-		1: 3,
-		2: 2,
-		3: 3,
+// ShipAt returns the ship covering (row, col), if any
+func (b *Board) ShipAt(row, col int) (*Ship, bool) {
+	if !b.IsValidPosition(row, col) {
+		return nil, false
+	}
+
+	id := b.ShipIDs[row][col]
+	if id == 0 {
+		return nil, false
 	}
 
-	currentStatus := b.Grid[row][col]
-	nextStatus := fireMap[currentStatus]
-	b.Grid[row][col] = nextStatus
+	return &b.Ships[id-1], true
+}
 
-	if nextStatus == 3 {
+// RemoveShip takes a placed ship back off the board, only before any shots are fired
+func (b *Board) RemoveShip(name string) (Ship, error) {
+	for i, ship := range b.Ships {
+		if !strings.EqualFold(ship.Class.Name, name) {
+			continue
+		}
+
+		for _, cell := range ship.Cells() {
+			b.Grid[cell[0]][cell[1]] = 0
+			b.ShipIDs[cell[0]][cell[1]] = 0
+		}
+
+		b.Ships = append(b.Ships[:i], b.Ships[i+1:]...)
 		b.ShipCount--
+
+		// the ships after it moved up a slot
+		for j := i; j < len(b.Ships); j++ {
+			for _, cell := range b.Ships[j].Cells() {
+				b.ShipIDs[cell[0]][cell[1]] = j + 1
+			}
+		}
+
+		return ship, nil
 	}
-	return nextStatus
+
+	return Ship{}, errors.New(name + " isn't placed")
+}
+
+func (b *Board) IsFleetComplete() bool {
+	return b.ShipCount >= len(Fleet)
+}
+
+// Fire shoots at (row, col), every cell can be fired at only once
+func (b *Board) Fire(row, col int) (ShotResult, error) {
+	if !b.IsValidPosition(row, col) {
+		return ShotResult{}, ErrOutOfBounds
+	}
+
+	if b.AlreadyFired(row, col) {
+		return ShotResult{}, ErrAlreadyFired
+	}
+
+	result := ShotResult{Row: row, Col: col, Outcome: Miss}
+	if b.Grid[row][col] == 0 {
+		b.Grid[row][col] = 2
+		return result, nil
+	}
+
+	b.Grid[row][col] = 3
+
+	ship, _ := b.ShipAt(row, col)
+	result.Outcome = Hit
+	result.Ship = ship
+
+	ship.Hits++
+	if ship.IsSunk() {
+		result.Outcome = Sunk
+		b.ShipCount--
+	}
+
+	return result, nil
+}
+
+// AlreadyFired reports whether (row, col) was shot at before, hit or miss
+func (b *Board) AlreadyFired(row, col int) bool {
+	return b.Grid[row][col] == 2 || b.Grid[row][col] == 3
 }
 
 func (b *Board) IsValidPosition(row, col int) bool {
-	if row >= 0 && row <= 9 && col >= 0 && col <= 9 {
+	if row >= 0 && row < b.Height && col >= 0 && col < b.Width {
 		return true
 	}
 
@@ -58,9 +196,55 @@ func (b *Board) IsValidPosition(row, col int) bool {
 }
 
 func (b *Board) AllShipDestroyed() bool {
-	if b.ShipCount <= 0 {
-		return true
+	// a ship cell that hasn't been hit yet means the fleet is still afloat
+	for row := range b.Grid {
+		for col := range b.Grid[row] {
+			if b.Grid[row][col] == 1 {
+				return false
+			}
+		}
 	}
 
-	return false
+	return true
+}
+
+// UnplacedShips lists the fleet classes that haven't been placed yet
+func (b *Board) UnplacedShips() []ShipClass {
+	var unplaced []ShipClass
+	for _, class := range Fleet {
+		placed := false
+		for _, ship := range b.Ships {
+			if ship.Class.Name == class.Name {
+				placed = true
+				break
+			}
+		}
+
+		if !placed {
+			unplaced = append(unplaced, class)
+		}
+	}
+
+	return unplaced
+}
+
+// PlaceRandomFleet drops every unplaced ship at a random legal spot
+func (b *Board) PlaceRandomFleet(r *rand.Rand) error {
+	for _, class := range b.UnplacedShips() {
+		placed := false
+
+		// plenty of room on any legal board, but don't spin forever on a crowded one
+		for attempt := 0; attempt < 1000 && !placed; attempt++ {
+			orientation := Orientation(r.IntN(2))
+			row := r.IntN(b.Height)
+			col := r.IntN(b.Width)
+			placed = b.PlaceShip(class, row, col, orientation) == nil
+		}
+
+		if !placed {
+			return errors.New("no room left for " + class.Name)
+		}
+	}
+
+	return nil
 }
@@ -44,10 +44,24 @@ func ConvertCell(cell string) (int, int, error) {
 	// karena baris dimulai dari 1, sedangkan index array dari 0
 	row--
 
-	// Validate range
-	if row < 0 || row > 9 || col < 0 || col > 9 {
+	// Validate range, the board itself checks its own width and height
+	if row < 0 || col < 0 {
 		return -1, -1, errors.New("Invalid coordinate")
 	}
 
 	return row, col, nil
 }
+
+// ColumnName is the reverse of the letter part of ConvertCell: 0 = A, 25 = Z, 26 = AA
+func ColumnName(col int) string {
+	name := ""
+	for col++; col > 0; col = (col - 1) / 26 {
+		name = string(rune('A'+(col-1)%26)) + name
+	}
+
+	return name
+}
+
+func CellName(row, col int) string {
+	return fmt.Sprintf("%s%d", ColumnName(col), row+1)
+}
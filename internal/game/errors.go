@@ -0,0 +1,18 @@
+package game
+
+import "errors"
+
+// Illegal moves the game rejects. Callers tell them apart with errors.Is,
+// the messages may carry more detail around them.
+var (
+	ErrWrongPhase      = errors.New("wrong phase for that move")
+	ErrNotYourTurn     = errors.New("not your turn")
+	ErrOutOfBounds     = errors.New("coordinate is outside the board")
+	ErrAlreadyFired    = errors.New("that cell has already been fired at")
+	ErrFleetComplete   = errors.New("fleet is already complete")
+	ErrFleetIncomplete = errors.New("fleet isn't complete yet")
+	ErrLockedIn        = errors.New("fleet is already locked in")
+	ErrShotCount       = errors.New("wrong number of shots for this turn")
+	ErrWrongMode       = errors.New("not available under these rules")
+	ErrNoCharges       = errors.New("no charges left")
+)
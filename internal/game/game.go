@@ -1,70 +1,215 @@
 package game
 
-// PHASE STATUS
-// PLACING
-// PLAYING
-// FINISHED
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
 
 type Game struct {
 	Player1    *Player
 	Player2    *Player
 	CurrPlayer int
-	Phase      string
+	Phase      Phase // only changes through transition, see phase.go
+	Rules      Rules
+	History    []Event // every placement, shot and phase change so far
+
+	// when the current placement or turn runs out, zero when untimed.
+	// Game only reports it, whoever runs the clock decides what happens then.
+	Deadline time.Time
+
+	Forfeited int // the player who gave up, 0 if nobody did
+
+	Charges [2]Charges // special weapons left under advanced rules, Player1 first
+
+	confirmed [2]bool // fleets locked in with ConfirmFleet, Player1 first
+
+	hooks       []Hook
+	subscribers []func(Event)
 }
 
 func NewGame(p1, p2 *Player) *Game {
+	return NewGameWithRules(p1, p2, DefaultRules())
+}
+
+// NewGameWithRules gives both players a fresh board sized by the rules
+func NewGameWithRules(p1, p2 *Player, rules Rules) *Game {
+	p1.Board = NewBoard(rules.Width, rules.Height)
+	p2.Board = NewBoard(rules.Width, rules.Height)
+
 	g := Game{
 		Player1:    p1,
 		Player2:    p2,
 		CurrPlayer: 1,
-		Phase:      "PLACING",
+		Phase:      PhaseLobby,
+		Rules:      rules,
 	}
 
+	if rules.Mode == ModeAdvanced {
+		g.Charges = [2]Charges{DefaultCharges(), DefaultCharges()}
+	}
+
+	g.record(Event{Kind: EventStart, Players: []string{p1.Name, p2.Name}, Rules: &rules})
+	g.transition(PhasePlacing)
+
 	return &g
 }
 
-func (g *Game) PlaceShipForPlayer(p *Player, cell string) bool {
+func (g *Game) PlaceShipForPlayer(p *Player, shipName, cell, orientation string) error {
+	if !g.Phase.Placing() {
+		return g.phaseError()
+	}
+
+	class, ok := FindShipClass(shipName)
+	if !ok {
+		return errors.New("unknown ship: " + shipName)
+	}
+
 	row, col, err := ConvertCell(cell)
 	if err != nil {
-		return false
+		return err
 	}
 
-	res := p.Board.PlaceShip(row, col)
+	o, err := ParseOrientation(orientation)
+	if err != nil {
+		return err
+	}
 
-	if g.Player1.Board.ShipCount == 5 && g.Player2.Board.ShipCount == 5 {
-		g.Phase = "PLAYING"
+	err = p.Board.PlaceShip(class, row, col, o)
+	if err != nil {
+		return err
 	}
 
-	return res
+	g.recordPlacement(p, p.Board.Ships[len(p.Board.Ships)-1])
+	g.fleetsPlaced()
+
+	return nil
 }
 
-func (g *Game) FireAtOpponent(firingPlayer *Player, cell string) int {
-	var opponent *Player
+// PlaceRandomFleetForPlayer finishes the player's placement at random
+func (g *Game) PlaceRandomFleetForPlayer(p *Player, r *rand.Rand) error {
+	if !g.Phase.Placing() {
+		return g.phaseError()
+	}
+
+	placed := len(p.Board.Ships)
 
-	if firingPlayer == g.Player1 {
-		opponent = g.Player2
-	} else {
-		opponent = g.Player1
+	err := p.Board.PlaceRandomFleet(r)
+
+	// record what made it onto the board, even if the fleet ran out of room
+	for _, ship := range p.Board.Ships[placed:] {
+		g.recordPlacement(p, ship)
 	}
 
+	if err != nil {
+		return err
+	}
+
+	g.fleetsPlaced()
+
+	return nil
+}
+
+func (g *Game) recordPlacement(p *Player, ship Ship) {
+	g.record(Event{
+		Kind:        EventPlace,
+		Player:      g.playerNumber(p),
+		Ship:        ship.Class.Name,
+		Cell:        CellName(ship.Row, ship.Col),
+		Orientation: ship.Orientation.String(),
+	})
+}
+
+func (g *Game) FireAtOpponent(firingPlayer *Player, cell string) (ShotResult, error) {
+	if err := g.checkTurn(firingPlayer); err != nil {
+		return ShotResult{}, err
+	}
+
+	if g.Rules.Mode == ModeSalvo {
+		return ShotResult{}, fmt.Errorf("single shots are %w, fire the whole salvo", ErrWrongMode)
+	}
+
+	opponent := g.opponentOf(firingPlayer)
+
 	row, col, err := ConvertCell(cell)
 
 	if err != nil {
-		return -1
+		return ShotResult{}, err
+	}
+
+	res, err := opponent.Board.Fire(row, col)
+	if err != nil {
+		return ShotResult{}, err
+	}
+
+	g.SwitchPlayer()
+
+	shot := Event{Kind: EventShot, Player: g.playerNumber(firingPlayer), Cell: CellName(row, col), Outcome: res.Outcome.String()}
+	if res.Outcome == Sunk {
+		shot.Sunk = res.Ship.Class.Name
+	}
+	g.record(shot)
+
+	g.finishIfOver()
+
+	return res, nil
+}
+
+// checkTurn is the check every shot goes through: combat has begun and it's p's turn
+func (g *Game) checkTurn(p *Player) error {
+	if g.Phase != PhasePlaying {
+		return g.phaseError()
 	}
 
-	if g.Phase == "PLAYING" {
-		g.SwitchPlayer()
+	if g.playerNumber(p) != g.CurrPlayer {
+		return ErrNotYourTurn
 	}
 
-	res := opponent.Board.Fire(row, col)
+	return nil
+}
+
+// phaseError is ErrWrongPhase with the reason the current phase gives
+func (g *Game) phaseError() error {
+	switch g.Phase {
+	case PhaseLobby, PhasePlacing, PhaseReadyCheck:
+		return fmt.Errorf("%w, ships are still being placed", ErrWrongPhase)
+	case PhasePlaying:
+		return fmt.Errorf("%w, combat has already begun", ErrWrongPhase)
+	default:
+		return fmt.Errorf("%w, the game is over", ErrWrongPhase)
+	}
+}
 
-	_, gameOver := g.IsGameOver()
-	if gameOver {
-		g.Phase = "FINISHED"
+func (g *Game) opponentOf(p *Player) *Player {
+	if p == g.Player1 {
+		return g.Player2
 	}
+	return g.Player1
+}
 
-	return res
+func (g *Game) currentPlayer() *Player {
+	if g.CurrPlayer == 2 {
+		return g.Player2
+	}
+	return g.Player1
+}
+
+// SkipTurn passes the turn to the opponent without a shot
+func (g *Game) SkipTurn() {
+	g.record(Event{Kind: EventSkip, Player: g.CurrPlayer})
+	g.SwitchPlayer()
+}
+
+// Forfeit ends the game with the other player as winner
+func (g *Game) Forfeit(p *Player) {
+	if !g.Phase.CanTransition(PhaseFinished) {
+		return
+	}
+
+	g.Forfeited = g.playerNumber(p)
+	g.record(Event{Kind: EventForfeit, Player: g.Forfeited})
+	g.transition(PhaseFinished)
 }
 
 func (g *Game) SwitchPlayer() int {
@@ -80,6 +225,10 @@ func (g *Game) SwitchPlayer() int {
 
 // winner, status
 func (g *Game) IsGameOver() (int, bool) {
+	if g.Forfeited != 0 {
+		return 3 - g.Forfeited, true
+	}
+
 	if g.Player1.Board.AllShipDestroyed() {
 		return 2, true
 	}
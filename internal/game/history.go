@@ -0,0 +1,223 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+type EventKind string
+
+const (
+	EventStart   EventKind = "start" // players and rules, always the first event
+	EventPlace   EventKind = "place"
+	EventRemove  EventKind = "remove"  // a placed ship taken back off the board
+	EventConfirm EventKind = "confirm" // the player locked their fleet in
+	EventShot    EventKind = "shot"
+	EventSalvo   EventKind = "salvo"   // a whole turn of shots under salvo rules
+	EventRadar   EventKind = "radar"   // advanced rules, Outcome is CONTACT or CLEAR
+	EventStrike  EventKind = "strike"  // advanced rules, Cell is the target, Cells what was hit
+	EventTorpedo EventKind = "torpedo" // advanced rules, Orientation holds the heading
+	EventPhase   EventKind = "phase"
+	EventSkip    EventKind = "skip"    // a turn passed without a shot, e.g. it timed out
+	EventForfeit EventKind = "forfeit" // the player gave the match up
+)
+
+// Event is one step of a match, in order they rebuild the whole game
+type Event struct {
+	Seq    int       `json:"seq"`
+	Time   time.Time `json:"time"`
+	Kind   EventKind `json:"kind"`
+	Player int       `json:"player,omitempty"` // 1 or 2
+
+	Players []string `json:"players,omitempty"` // start
+	Rules   *Rules   `json:"rules,omitempty"`   // start
+
+	Ship        string `json:"ship,omitempty"`        // place, remove
+	Cell        string `json:"cell,omitempty"`        // place, shot, radar, strike, torpedo
+	Orientation string `json:"orientation,omitempty"` // place, torpedo
+	Outcome     string `json:"outcome,omitempty"`     // shot, radar
+	Sunk        string `json:"sunk,omitempty"`        // shot, the ship that went down
+
+	Cells    []string `json:"cells,omitempty"`    // salvo, strike, torpedo
+	Outcomes []string `json:"outcomes,omitempty"` // salvo, strike, torpedo, one per cell
+
+	Phase Phase `json:"phase,omitempty"` // phase
+}
+
+func (g *Game) record(e Event) {
+	e.Seq = len(g.History) + 1
+	e.Time = time.Now()
+	g.History = append(g.History, e)
+
+	for _, fn := range g.subscribers {
+		if fn != nil {
+			fn(e)
+		}
+	}
+}
+
+func (g *Game) playerNumber(p *Player) int {
+	if p == g.Player2 {
+		return 2
+	}
+	return 1
+}
+
+func (g *Game) playerByNumber(n int) (*Player, error) {
+	switch n {
+	case 1:
+		return g.Player1, nil
+	case 2:
+		return g.Player2, nil
+	default:
+		return nil, fmt.Errorf("no player %d", n)
+	}
+}
+
+// ShotStats counts the shots a player fired and how many of them hit. A torpedo
+// counts as one shot, whatever water it crossed on the way.
+func (g *Game) ShotStats(p *Player) (shots, hits int) {
+	player := g.playerNumber(p)
+	for _, e := range g.History {
+		if e.Player != player {
+			continue
+		}
+
+		var outcomes []string
+		switch e.Kind {
+		case EventShot:
+			outcomes = []string{e.Outcome}
+		case EventSalvo, EventStrike:
+			outcomes = e.Outcomes
+		case EventTorpedo:
+			if len(e.Outcomes) > 0 {
+				outcomes = e.Outcomes[len(e.Outcomes)-1:]
+			}
+		}
+
+		for _, outcome := range outcomes {
+			shots++
+			if outcome != Miss.String() {
+				hits++
+			}
+		}
+	}
+
+	return shots, hits
+}
+
+// NewGameFromHistory starts a game from the start event of a recorded match,
+// the remaining events can then be fed to Apply one by one
+func NewGameFromHistory(start Event) (*Game, error) {
+	if start.Kind != EventStart || len(start.Players) != 2 || start.Rules == nil {
+		return nil, errors.New("history doesn't begin with a start event")
+	}
+
+	if err := start.Rules.Validate(); err != nil {
+		return nil, err
+	}
+
+	p1 := &Player{Name: start.Players[0]}
+	p2 := &Player{Name: start.Players[1]}
+
+	return NewGameWithRules(p1, p2, *start.Rules), nil
+}
+
+// Apply replays one recorded event. Phase changes follow from placements and
+// shots, so phase events are only checked against the phases the game itself
+// just went through. Only an abort has nothing else to follow from.
+func (g *Game) Apply(e Event) error {
+	switch e.Kind {
+	case EventPlace:
+		p, err := g.playerByNumber(e.Player)
+		if err != nil {
+			return err
+		}
+		return g.PlaceShipForPlayer(p, e.Ship, e.Cell, e.Orientation)
+
+	case EventRemove:
+		p, err := g.playerByNumber(e.Player)
+		if err != nil {
+			return err
+		}
+		_, err = g.RemoveShipForPlayer(p, e.Ship)
+		return err
+
+	case EventConfirm:
+		p, err := g.playerByNumber(e.Player)
+		if err != nil {
+			return err
+		}
+		return g.ConfirmFleet(p)
+
+	case EventShot:
+		p, err := g.playerByNumber(e.Player)
+		if err != nil {
+			return err
+		}
+		_, err = g.FireAtOpponent(p, e.Cell)
+		return err
+
+	case EventSalvo:
+		p, err := g.playerByNumber(e.Player)
+		if err != nil {
+			return err
+		}
+		_, err = g.FireSalvo(p, e.Cells)
+		return err
+
+	case EventRadar, EventStrike, EventTorpedo:
+		p, err := g.playerByNumber(e.Player)
+		if err != nil {
+			return err
+		}
+		switch e.Kind {
+		case EventRadar:
+			_, err = g.Radar(p, e.Cell)
+		case EventStrike:
+			_, err = g.Airstrike(p, e.Cell)
+		default:
+			_, err = g.Torpedo(p, e.Cell, e.Orientation)
+		}
+		return err
+
+	case EventSkip:
+		g.SkipTurn()
+		return nil
+
+	case EventForfeit:
+		p, err := g.playerByNumber(e.Player)
+		if err != nil {
+			return err
+		}
+		g.Forfeit(p)
+		return nil
+
+	case EventPhase:
+		if e.Phase == PhaseAborted {
+			g.Abort()
+		}
+
+		// replays from before the ready check have no confirm events,
+		// their fleets count as locked in once complete
+		if e.Phase == PhasePlaying && g.Phase == PhaseReadyCheck {
+			for _, p := range []*Player{g.Player1, g.Player2} {
+				if !g.Confirmed(p) {
+					g.ConfirmFleet(p)
+				}
+			}
+		}
+
+		// the phase events since the last move
+		for i := len(g.History) - 1; i >= 0 && g.History[i].Kind == EventPhase; i-- {
+			if g.History[i].Phase == e.Phase {
+				return nil
+			}
+		}
+		return fmt.Errorf("event %d: expected phase %s, game is %s", e.Seq, e.Phase, g.Phase)
+
+	default:
+		return fmt.Errorf("event %d: unexpected %s event", e.Seq, e.Kind)
+	}
+}
@@ -0,0 +1,112 @@
+package game
+
+import "fmt"
+
+// Phase is where a game stands, it only ever moves along the transitions table
+type Phase string
+
+const (
+	PhaseLobby      Phase = "LOBBY"       // players known, nothing placed yet
+	PhasePlacing    Phase = "PLACING"     // fleets going onto the boards
+	PhaseReadyCheck Phase = "READY_CHECK" // both fleets complete, waiting to start combat
+	PhasePlaying    Phase = "PLAYING"
+	PhaseFinished   Phase = "FINISHED" // somebody won, by sinking the fleet or a forfeit
+	PhaseAborted    Phase = "ABORTED"  // called off without a result
+)
+
+// transitions lists the phases each phase may move on to. Forfeits end a
+// game from any phase a player is in, aborts from any phase that isn't over.
+var transitions = map[Phase][]Phase{
+	PhaseLobby:      {PhasePlacing, PhaseAborted},
+	PhasePlacing:    {PhaseReadyCheck, PhaseFinished, PhaseAborted},
+	PhaseReadyCheck: {PhasePlaying, PhasePlacing, PhaseFinished, PhaseAborted},
+	PhasePlaying:    {PhaseFinished, PhaseAborted},
+}
+
+// CanTransition reports whether the transitions table allows moving from p to next
+func (p Phase) CanTransition(next Phase) bool {
+	for _, allowed := range transitions[p] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// Placing reports whether fleets may still change in p: while they go onto
+// the boards, and during the ready check until each player confirms
+func (p Phase) Placing() bool {
+	return p == PhasePlacing || p == PhaseReadyCheck
+}
+
+// Over reports whether p ends the game, nothing moves on from it
+func (p Phase) Over() bool {
+	return len(transitions[p]) == 0
+}
+
+// Hook runs right after a game moves from one phase to another
+type Hook func(from, to Phase)
+
+// OnTransition adds a hook that runs on every phase change from now on
+func (g *Game) OnTransition(hook Hook) {
+	g.hooks = append(g.hooks, hook)
+}
+
+// Events subscribes fn to every event the game records from now on, phase
+// changes included. fn runs synchronously inside the call that recorded the
+// event, so it sees the game mid-move and must not make moves itself.
+func (g *Game) Events(fn func(Event)) (unsubscribe func()) {
+	g.subscribers = append(g.subscribers, fn)
+	i := len(g.subscribers) - 1
+
+	return func() {
+		g.subscribers[i] = nil
+	}
+}
+
+func (g *Game) transition(to Phase) error {
+	from := g.Phase
+	if !from.CanTransition(to) {
+		return fmt.Errorf("%w: %s can't move on to %s", ErrWrongPhase, from, to)
+	}
+
+	g.Phase = to
+	g.record(Event{Kind: EventPhase, Phase: to})
+
+	for _, hook := range g.hooks {
+		hook(from, to)
+	}
+
+	return nil
+}
+
+// fleetsPlaced holds the ready check once both fleets are complete, and
+// starts combat once both players have confirmed theirs
+func (g *Game) fleetsPlaced() {
+	if !g.Phase.Placing() || !g.Player1.Board.IsFleetComplete() || !g.Player2.Board.IsFleetComplete() {
+		return
+	}
+
+	if g.Phase == PhasePlacing {
+		g.transition(PhaseReadyCheck)
+	}
+
+	if g.confirmed[0] && g.confirmed[1] {
+		g.transition(PhasePlaying)
+	}
+}
+
+// finishIfOver ends the game once a fleet is gone
+func (g *Game) finishIfOver() {
+	if _, gameOver := g.IsGameOver(); gameOver && g.Phase.CanTransition(PhaseFinished) {
+		g.transition(PhaseFinished)
+	}
+}
+
+// Abort calls the game off without a winner, e.g. when a player leaves
+// before it's decided. A game that's already over stays as it is.
+func (g *Game) Abort() {
+	if g.Phase.CanTransition(PhaseAborted) {
+		g.transition(PhaseAborted)
+	}
+}
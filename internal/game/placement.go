@@ -0,0 +1,96 @@
+package game
+
+import (
+	"errors"
+	"strings"
+)
+
+// Confirmed reports whether p has locked their fleet in
+func (g *Game) Confirmed(p *Player) bool {
+	return g.confirmed[g.playerNumber(p)-1]
+}
+
+// RemoveShipForPlayer takes one of p's ships back off the board. A fleet
+// that's been confirmed is locked in and stays as it is.
+func (g *Game) RemoveShipForPlayer(p *Player, shipName string) (Ship, error) {
+	if !g.Phase.Placing() {
+		return Ship{}, g.phaseError()
+	}
+
+	if g.Confirmed(p) {
+		return Ship{}, ErrLockedIn
+	}
+
+	ship, err := p.Board.RemoveShip(shipName)
+	if err != nil {
+		return Ship{}, err
+	}
+
+	g.record(Event{Kind: EventRemove, Player: g.playerNumber(p), Ship: ship.Class.Name})
+
+	// a fleet came apart, the ready check is off
+	if g.Phase == PhaseReadyCheck {
+		g.transition(PhasePlacing)
+	}
+
+	return ship, nil
+}
+
+// RemoveShipAtForPlayer takes back whichever of p's ships covers cell
+func (g *Game) RemoveShipAtForPlayer(p *Player, cell string) (Ship, error) {
+	row, col, err := ConvertCell(cell)
+	if err != nil {
+		return Ship{}, err
+	}
+
+	ship, ok := p.Board.ShipAt(row, col)
+	if !ok {
+		if !p.Board.IsValidPosition(row, col) {
+			return Ship{}, ErrOutOfBounds
+		}
+		return Ship{}, errors.New("no ship at " + strings.ToUpper(cell))
+	}
+
+	return g.RemoveShipForPlayer(p, ship.Class.Name)
+}
+
+// ClearFleetForPlayer takes all of p's ships back off the board
+func (g *Game) ClearFleetForPlayer(p *Player) error {
+	if !g.Phase.Placing() {
+		return g.phaseError()
+	}
+
+	if g.Confirmed(p) {
+		return ErrLockedIn
+	}
+
+	for len(p.Board.Ships) > 0 {
+		if _, err := g.RemoveShipForPlayer(p, p.Board.Ships[0].Class.Name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ConfirmFleet locks p's complete fleet in, combat starts once both players have
+func (g *Game) ConfirmFleet(p *Player) error {
+	if !g.Phase.Placing() {
+		return g.phaseError()
+	}
+
+	if g.Confirmed(p) {
+		return ErrLockedIn
+	}
+
+	if !p.Board.IsFleetComplete() {
+		return ErrFleetIncomplete
+	}
+
+	g.confirmed[g.playerNumber(p)-1] = true
+	g.record(Event{Kind: EventConfirm, Player: g.playerNumber(p)})
+
+	g.fleetsPlaced()
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	MinBoardSize     = 5
+	MaxBoardSize     = 26 // one letter per column, A..Z
+	DefaultBoardSize = 10
+)
+
+// Mode is the rules variant a match is played under
+type Mode string
+
+const (
+	ModeClassic  Mode = "classic"  // one shot per turn
+	ModeSalvo    Mode = "salvo"    // one shot per ship still afloat, fired together
+	ModeAdvanced Mode = "advanced" // classic turns plus a few radar, strike and torpedo charges
+)
+
+func ParseMode(s string) (Mode, error) {
+	switch mode := Mode(strings.ToLower(strings.TrimSpace(s))); mode {
+	case ModeClassic, ModeSalvo, ModeAdvanced:
+		return mode, nil
+	default:
+		return "", errors.New("unknown mode: expected classic, salvo or advanced")
+	}
+}
+
+// Rules holds the per-match settings both players agree on
+type Rules struct {
+	Width  int  `json:"width"`
+	Height int  `json:"height"`
+	Mode   Mode `json:"mode,omitempty"` // empty plays classic
+}
+
+func DefaultRules() Rules {
+	return Rules{
+		Width:  DefaultBoardSize,
+		Height: DefaultBoardSize,
+		Mode:   ModeClassic,
+	}
+}
+
+func (r Rules) Validate() error {
+	if r.Width < MinBoardSize || r.Width > MaxBoardSize || r.Height < MinBoardSize || r.Height > MaxBoardSize {
+		return fmt.Errorf("board size must be between %dx%d and %dx%d", MinBoardSize, MinBoardSize, MaxBoardSize, MaxBoardSize)
+	}
+
+	if r.Mode != "" {
+		if _, err := ParseMode(string(r.Mode)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ParseBoardSize accepts "12" for a square board or "12x8" for width x height
+func ParseBoardSize(s string) (int, int, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+
+	var width, height int
+	if strings.Contains(s, "x") {
+		_, err := fmt.Sscanf(s, "%dx%d", &width, &height)
+		if err != nil {
+			return 0, 0, errors.New("invalid board size: expected N or WxH")
+		}
+	} else {
+		_, err := fmt.Sscanf(s, "%d", &width)
+		if err != nil {
+			return 0, 0, errors.New("invalid board size: expected N or WxH")
+		}
+		height = width
+	}
+
+	r := Rules{Width: width, Height: height}
+	if err := r.Validate(); err != nil {
+		return 0, 0, err
+	}
+
+	return width, height, nil
+}
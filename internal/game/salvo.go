@@ -0,0 +1,77 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ShotsAllowed is how many shots p fires per turn: one in classic,
+// one per ship still afloat under salvo rules
+func (g *Game) ShotsAllowed(p *Player) int {
+	if g.Rules.Mode != ModeSalvo {
+		return 1
+	}
+
+	return p.Board.ShipCount
+}
+
+// FireSalvo fires a whole turn at once. Every cell is checked before any is
+// fired, so a bad cell costs nothing.
+func (g *Game) FireSalvo(firingPlayer *Player, cells []string) ([]ShotResult, error) {
+	if err := g.checkTurn(firingPlayer); err != nil {
+		return nil, err
+	}
+
+	if g.Rules.Mode != ModeSalvo {
+		return nil, fmt.Errorf("salvos are %w", ErrWrongMode)
+	}
+
+	allowed := g.ShotsAllowed(firingPlayer)
+	if len(cells) != allowed {
+		return nil, fmt.Errorf("%w: fire exactly %d, one per ship afloat", ErrShotCount, allowed)
+	}
+
+	opponent := g.opponentOf(firingPlayer)
+
+	targets := make([][2]int, 0, len(cells))
+	seen := make(map[[2]int]bool, len(cells))
+	for _, cell := range cells {
+		row, col, err := ConvertCell(cell)
+		if err != nil {
+			return nil, err
+		}
+
+		if !opponent.Board.IsValidPosition(row, col) {
+			return nil, fmt.Errorf("%s: %w", cell, ErrOutOfBounds)
+		}
+
+		if opponent.Board.AlreadyFired(row, col) {
+			return nil, fmt.Errorf("%s: %w", cell, ErrAlreadyFired)
+		}
+
+		target := [2]int{row, col}
+		if seen[target] {
+			return nil, errors.New(cell + " is targeted twice")
+		}
+		seen[target] = true
+		targets = append(targets, target)
+	}
+
+	g.SwitchPlayer()
+
+	salvo := Event{Kind: EventSalvo, Player: g.playerNumber(firingPlayer)}
+	results := make([]ShotResult, 0, len(targets))
+	for _, target := range targets {
+		// every target was checked above, and none of them twice
+		res, _ := opponent.Board.Fire(target[0], target[1])
+		results = append(results, res)
+
+		salvo.Cells = append(salvo.Cells, CellName(target[0], target[1]))
+		salvo.Outcomes = append(salvo.Outcomes, res.Outcome.String())
+	}
+	g.record(salvo)
+
+	g.finishIfOver()
+
+	return results, nil
+}
@@ -0,0 +1,95 @@
+package game
+
+import (
+	"errors"
+	"testing"
+)
+
+// battle starts a match under mode with both fleets laid out the same way,
+// one ship per row from the Carrier on A1-E1 down to the Destroyer on A5-B5
+func battle(t *testing.T, mode Mode) (*Game, *Player, *Player) {
+	t.Helper()
+
+	rules := DefaultRules()
+	rules.Mode = mode
+
+	p1, p2 := &Player{Name: "alice"}, &Player{Name: "bob"}
+	g := NewGameWithRules(p1, p2, rules)
+
+	for _, p := range []*Player{p1, p2} {
+		for i, class := range Fleet {
+			if err := g.PlaceShipForPlayer(p, class.Name, CellName(i, 0), "H"); err != nil {
+				t.Fatalf("placing the %s: %v", class.Name, err)
+			}
+		}
+		if err := g.ConfirmFleet(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return g, p1, p2
+}
+
+func TestSalvoShrinksWithFleet(t *testing.T) {
+	g, p1, p2 := battle(t, ModeSalvo)
+
+	if got := g.ShotsAllowed(p1); got != len(Fleet) {
+		t.Fatalf("ShotsAllowed = %d with the whole fleet afloat, want %d", got, len(Fleet))
+	}
+
+	// alice sinks bob's Destroyer with the first two shots
+	results, err := g.FireSalvo(p1, []string{"A5", "B5", "J10", "J9", "J8"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results[1].Outcome != Sunk {
+		t.Fatalf("second shot %v, want SUNK", results[1].Outcome)
+	}
+
+	if got := g.ShotsAllowed(p2); got != len(Fleet)-1 {
+		t.Errorf("ShotsAllowed = %d after losing a ship, want %d", got, len(Fleet)-1)
+	}
+	if got := g.ShotsAllowed(p1); got != len(Fleet) {
+		t.Errorf("ShotsAllowed = %d for the side that lost nothing, want %d", got, len(Fleet))
+	}
+
+	if _, err := g.FireSalvo(p2, []string{"J10", "J9", "J8", "J7", "J6"}); !errors.Is(err, ErrShotCount) {
+		t.Errorf("a shot per ship before the loss = %v, want ErrShotCount", err)
+	}
+	if _, err := g.FireSalvo(p2, []string{"J10", "J9", "J8", "J7"}); err != nil {
+		t.Errorf("a shot per ship afloat: %v", err)
+	}
+}
+
+func TestSalvoRejectsBadTargets(t *testing.T) {
+	tests := []struct {
+		name  string
+		cells []string
+		err   error // nil for any error
+	}{
+		{"duplicate", []string{"A1", "B1", "a1", "C1", "D1"}, nil},
+		{"off the board", []string{"A1", "B1", "C1", "D1", "K1"}, ErrOutOfBounds},
+		{"too few", []string{"A1", "B1", "C1", "D1"}, ErrShotCount},
+		{"too many", []string{"A1", "B1", "C1", "D1", "E1", "F1"}, ErrShotCount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, p1, p2 := battle(t, ModeSalvo)
+			events := len(g.History)
+
+			_, err := g.FireSalvo(p1, tt.cells)
+			if err == nil || (tt.err != nil && !errors.Is(err, tt.err)) {
+				t.Fatalf("FireSalvo(%v) = %v, want %v", tt.cells, err, tt.err)
+			}
+
+			// a rejected salvo fires nothing and keeps the turn
+			if g.CurrPlayer != 1 {
+				t.Errorf("turn passed to player %d", g.CurrPlayer)
+			}
+			if p2.Board.AlreadyFired(0, 0) || p2.Board.ShipCount != len(Fleet) || len(g.History) != events {
+				t.Errorf("rejected salvo left a mark, %d events recorded", len(g.History)-events)
+			}
+		})
+	}
+}
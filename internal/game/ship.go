@@ -0,0 +1,94 @@
+package game
+
+import (
+	"errors"
+	"strings"
+)
+
+type Orientation int
+
+const (
+	Horizontal Orientation = iota
+	Vertical
+)
+
+func (o Orientation) String() string {
+	if o == Vertical {
+		return "V"
+	}
+
+	return "H"
+}
+
+func ParseOrientation(s string) (Orientation, error) {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case "H", "HORIZONTAL":
+		return Horizontal, nil
+	case "V", "VERTICAL":
+		return Vertical, nil
+	default:
+		return Horizontal, errors.New("invalid orientation: expected H or V")
+	}
+}
+
+type ShipClass struct {
+	Name string `json:"name"`
+	Size int    `json:"size"`
+}
+
+// classic fleet, every player places each class exactly once
+var Fleet = []ShipClass{
+	{Name: "Carrier", Size: 5},
+	{Name: "Battleship", Size: 4},
+	{Name: "Cruiser", Size: 3},
+	{Name: "Submarine", Size: 3},
+	{Name: "Destroyer", Size: 2},
+}
+
+func FindShipClass(name string) (ShipClass, bool) {
+	name = strings.TrimSpace(name)
+	for _, class := range Fleet {
+		if strings.EqualFold(class.Name, name) {
+			return class, true
+		}
+	}
+
+	return ShipClass{}, false
+}
+
+type Ship struct {
+	Class       ShipClass   `json:"class"`
+	Row         int         `json:"row"`
+	Col         int         `json:"col"`
+	Orientation Orientation `json:"orientation"`
+	Hits        int         `json:"hits"`
+}
+
+func (s Ship) IsSunk() bool {
+	return s.Hits >= s.Class.Size
+}
+
+// Cells returns every (row, col) covered by the ship, from bow to stern
+func (s Ship) Cells() [][2]int {
+	cells := make([][2]int, 0, s.Class.Size)
+	for i := 0; i < s.Class.Size; i++ {
+		if s.Orientation == Vertical {
+			cells = append(cells, [2]int{s.Row + i, s.Col})
+		} else {
+			cells = append(cells, [2]int{s.Row, s.Col + i})
+		}
+	}
+
+	return cells
+}
+
+// Segment returns the index of (row, col) inside the ship, or -1 when the ship doesn't cover it
+func (s Ship) Segment(row, col int) int {
+	for i, cell := range s.Cells() {
+		if cell[0] == row && cell[1] == col {
+			return i
+		}
+	}
+
+	return -1
+}
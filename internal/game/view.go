@@ -0,0 +1,194 @@
+package game
+
+import "time"
+
+// BoardView is a board as one particular viewer is allowed to see it
+type BoardView struct {
+	Name      string      `json:"name"`
+	Width     int         `json:"width"`
+	Height    int         `json:"height"`
+	Grid      [][]int     `json:"grid"`            // same cell states as Board.Grid, unknown cells read as 0
+	Ships     []Ship      `json:"ships,omitempty"` // every ship for the owner, only sunk ones for the opponent
+	ShipsLeft int         `json:"ships_left"`
+	Unplaced  []ShipClass `json:"unplaced,omitempty"`
+}
+
+// View is a per-player snapshot of a game, safe to send over the wire
+type View struct {
+	Phase    Phase     `json:"phase"`
+	YourTurn bool      `json:"your_turn"` // for spectators: Player1's turn
+	Own      BoardView `json:"own"`
+	Opponent BoardView `json:"opponent"`
+
+	// a spectator's neutral view, Own is Player1 and Opponent is Player2
+	Spectating bool `json:"spectating,omitempty"`
+
+	TimeLeft int `json:"time_left,omitempty"` // seconds left for the current placement or turn, 0 when untimed
+
+	// fleets locked in during placement, for spectators Player1's and Player2's
+	Confirmed         bool `json:"confirmed,omitempty"`
+	OpponentConfirmed bool `json:"opponent_confirmed,omitempty"`
+
+	Mode         Mode `json:"mode,omitempty"`
+	ShotsAllowed int  `json:"shots_allowed,omitempty"` // salvo: shots in your turn, for spectators the current player's
+
+	Charges *Charges `json:"charges,omitempty"` // advanced: your special weapons left, for spectators the current player's
+
+	Series *SeriesScore `json:"series,omitempty"` // filled in by the server for best-of series
+}
+
+// SeriesScore is the running score of a best-of series of rematches,
+// for spectators from Player1's side
+type SeriesScore struct {
+	BestOf       int `json:"best_of"`
+	Game         int `json:"game"` // this match's number in the series
+	Wins         int `json:"wins"`
+	OpponentWins int `json:"opponent_wins"`
+}
+
+func (g *Game) ViewFor(p *Player) View {
+	opponent := g.Player2
+	turn := 1
+	if p == g.Player2 {
+		opponent = g.Player1
+		turn = 2
+	}
+
+	return View{
+		Phase:    g.Phase,
+		YourTurn: g.CurrPlayer == turn,
+		Own:      OwnBoardView(p),
+		Opponent: FoggedBoardView(opponent),
+		TimeLeft: g.timeLeft(),
+
+		Confirmed:         g.Confirmed(p),
+		OpponentConfirmed: g.Confirmed(opponent),
+
+		Mode:         g.Rules.Mode,
+		ShotsAllowed: g.salvoAllowance(p),
+		Charges:      g.chargesView(p),
+	}
+}
+
+// salvoAllowance is ShotsAllowed for salvo views and left out of classic ones
+func (g *Game) salvoAllowance(p *Player) int {
+	if g.Rules.Mode != ModeSalvo {
+		return 0
+	}
+	return g.ShotsAllowed(p)
+}
+
+// chargesView is left out of views outside advanced rules
+func (g *Game) chargesView(p *Player) *Charges {
+	if g.Rules.Mode != ModeAdvanced {
+		return nil
+	}
+
+	charges := g.ChargesFor(p)
+	return &charges
+}
+
+// timeLeft rounds up, so a view never claims 0 seconds before time is actually up
+func (g *Game) timeLeft() int {
+	if g.Deadline.IsZero() || (!g.Phase.Placing() && g.Phase != PhasePlaying) {
+		return 0
+	}
+
+	left := time.Until(g.Deadline)
+	if left <= 0 {
+		return 0
+	}
+
+	return int((left + time.Second - 1) / time.Second)
+}
+
+// RevealedView is Player1's view without the fog, both fleets in full
+func (g *Game) RevealedView() View {
+	v := g.ViewFor(g.Player1)
+	v.Opponent = OwnBoardView(g.Player2)
+	return v
+}
+
+// SpectatorView watches from neither side, showing both fleets or only the shots fired
+func (g *Game) SpectatorView(reveal bool) View {
+	board := FoggedBoardView
+	if reveal {
+		board = OwnBoardView
+	}
+
+	return View{
+		Phase:      g.Phase,
+		YourTurn:   g.CurrPlayer == 1,
+		Own:        board(g.Player1),
+		Opponent:   board(g.Player2),
+		Spectating: true,
+		TimeLeft:   g.timeLeft(),
+
+		Confirmed:         g.Confirmed(g.Player1),
+		OpponentConfirmed: g.Confirmed(g.Player2),
+
+		Mode:         g.Rules.Mode,
+		ShotsAllowed: g.salvoAllowance(g.currentPlayer()),
+		Charges:      g.chargesView(g.currentPlayer()),
+	}
+}
+
+// OwnBoardView reveals everything, it's what a player sees of their own fleet
+func OwnBoardView(p *Player) BoardView {
+	b := p.Board
+	v := BoardView{
+		Name:      p.Name,
+		Width:     b.Width,
+		Height:    b.Height,
+		Grid:      make([][]int, b.Height),
+		Ships:     append([]Ship(nil), b.Ships...),
+		ShipsLeft: b.ShipCount,
+		Unplaced:  b.UnplacedShips(),
+	}
+
+	for row := range b.Grid {
+		v.Grid[row] = append([]int(nil), b.Grid[row]...)
+	}
+
+	return v
+}
+
+// FoggedBoardView only shows shots already fired and ships already sunk
+func FoggedBoardView(p *Player) BoardView {
+	b := p.Board
+	v := BoardView{
+		Name:      p.Name,
+		Width:     b.Width,
+		Height:    b.Height,
+		Grid:      make([][]int, b.Height),
+		ShipsLeft: b.ShipCount,
+	}
+
+	for row := range b.Grid {
+		v.Grid[row] = make([]int, b.Width)
+		for col, cell := range b.Grid[row] {
+			if cell == 2 || cell == 3 { // only HIT/MISS are known
+				v.Grid[row][col] = cell
+			}
+		}
+	}
+
+	for _, ship := range b.Ships {
+		if ship.IsSunk() {
+			v.Ships = append(v.Ships, ship)
+		}
+	}
+
+	return v
+}
+
+// ShipAt finds the visible ship covering (row, col), if any
+func (v BoardView) ShipAt(row, col int) (*Ship, bool) {
+	for i := range v.Ships {
+		if v.Ships[i].Segment(row, col) >= 0 {
+			return &v.Ships[i], true
+		}
+	}
+
+	return nil, false
+}
@@ -0,0 +1,225 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Weapon is a limited-use ability of the advanced rules, used instead of a shot
+type Weapon string
+
+const (
+	WeaponRadar   Weapon = "radar"   // reports whether any ship is afloat in a 3x3 area
+	WeaponStrike  Weapon = "strike"  // fires at a row segment of StrikeLength cells
+	WeaponTorpedo Weapon = "torpedo" // runs along a line until it hits a ship
+)
+
+// StrikeLength is how many cells of a row an airstrike covers, centred on its target
+const StrikeLength = 3
+
+// radar answers, recorded as the outcome of a radar event
+const (
+	RadarContact = "CONTACT"
+	RadarClear   = "CLEAR"
+)
+
+// Charges counts the special weapons a player has left
+type Charges struct {
+	Radar   int `json:"radar"`
+	Strike  int `json:"strike"`
+	Torpedo int `json:"torpedo"`
+}
+
+// DefaultCharges is what each player starts an advanced match with
+func DefaultCharges() Charges {
+	return Charges{Radar: 2, Strike: 1, Torpedo: 1}
+}
+
+func (c *Charges) count(w Weapon) *int {
+	switch w {
+	case WeaponRadar:
+		return &c.Radar
+	case WeaponStrike:
+		return &c.Strike
+	default:
+		return &c.Torpedo
+	}
+}
+
+// Left is how many uses of w remain
+func (c Charges) Left(w Weapon) int {
+	return *c.count(w)
+}
+
+// ChargesFor is what p has left, the zero value outside advanced rules
+func (g *Game) ChargesFor(p *Player) Charges {
+	return g.Charges[g.playerNumber(p)-1]
+}
+
+// weaponTarget checks that p may use w at cell and returns the opponent and
+// the target. Nothing is spent until the weapon actually goes off.
+func (g *Game) weaponTarget(p *Player, w Weapon, cell string) (*Player, int, int, error) {
+	if err := g.checkTurn(p); err != nil {
+		return nil, 0, 0, err
+	}
+
+	if g.Rules.Mode != ModeAdvanced {
+		return nil, 0, 0, fmt.Errorf("special weapons are %w", ErrWrongMode)
+	}
+
+	if g.ChargesFor(p).Left(w) <= 0 {
+		return nil, 0, 0, fmt.Errorf("%w: %s", ErrNoCharges, w)
+	}
+
+	opponent := g.opponentOf(p)
+
+	row, col, err := ConvertCell(cell)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	if !opponent.Board.IsValidPosition(row, col) {
+		return nil, 0, 0, ErrOutOfBounds
+	}
+
+	return opponent, row, col, nil
+}
+
+// useWeapon spends a charge and the turn
+func (g *Game) useWeapon(p *Player, w Weapon) {
+	charges := &g.Charges[g.playerNumber(p)-1]
+	*charges.count(w)--
+
+	g.SwitchPlayer()
+}
+
+// Radar sweeps the 3x3 area around cell for ship segments that haven't been
+// hit yet. The sweep leaves the grid untouched, only the answer is recorded.
+func (g *Game) Radar(p *Player, cell string) (bool, error) {
+	opponent, row, col, err := g.weaponTarget(p, WeaponRadar, cell)
+	if err != nil {
+		return false, err
+	}
+
+	g.useWeapon(p, WeaponRadar)
+
+	contact := false
+	for r := row - 1; r <= row+1; r++ {
+		for c := col - 1; c <= col+1; c++ {
+			if opponent.Board.IsValidPosition(r, c) && opponent.Board.Grid[r][c] == 1 {
+				contact = true
+			}
+		}
+	}
+
+	outcome := RadarClear
+	if contact {
+		outcome = RadarContact
+	}
+	g.record(Event{Kind: EventRadar, Player: g.playerNumber(p), Cell: CellName(row, col), Outcome: outcome})
+
+	return contact, nil
+}
+
+// Airstrike fires at StrikeLength cells of cell's row, centred on cell and cut
+// short by the edge of the board. Cells already fired at are left out, a
+// strike with nothing new to hit is refused.
+func (g *Game) Airstrike(p *Player, cell string) ([]ShotResult, error) {
+	opponent, row, col, err := g.weaponTarget(p, WeaponStrike, cell)
+	if err != nil {
+		return nil, err
+	}
+
+	var targets []int
+	for c := col - StrikeLength/2; c < col-StrikeLength/2+StrikeLength; c++ {
+		if opponent.Board.IsValidPosition(row, c) && !opponent.Board.AlreadyFired(row, c) {
+			targets = append(targets, c)
+		}
+	}
+
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("every cell of a strike on %s: %w", CellName(row, col), ErrAlreadyFired)
+	}
+
+	g.useWeapon(p, WeaponStrike)
+
+	strike := Event{Kind: EventStrike, Player: g.playerNumber(p), Cell: CellName(row, col)}
+	var results []ShotResult
+	for _, c := range targets {
+		res, _ := opponent.Board.Fire(row, c)
+		results = append(results, res)
+
+		strike.Cells = append(strike.Cells, CellName(row, c))
+		strike.Outcomes = append(strike.Outcomes, res.Outcome.String())
+	}
+	g.record(strike)
+
+	g.finishIfOver()
+
+	return results, nil
+}
+
+// ParseHeading turns up, down, left or right into a step across the board
+func ParseHeading(s string) (dRow, dCol int, err error) {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case "U", "UP", "N", "NORTH":
+		return -1, 0, nil
+	case "D", "DOWN", "S", "SOUTH":
+		return 1, 0, nil
+	case "L", "LEFT", "W", "WEST":
+		return 0, -1, nil
+	case "R", "RIGHT", "E", "EAST":
+		return 0, 1, nil
+	default:
+		return 0, 0, errors.New("invalid heading: expected up, down, left or right")
+	}
+}
+
+// Torpedo runs from cell towards heading until it meets a ship segment that
+// hasn't been hit yet, or leaves the board. Open water it crosses is marked as
+// a miss, cells already fired at are passed over. Like a strike, a run with
+// nothing new to hit is refused.
+func (g *Game) Torpedo(p *Player, cell, heading string) ([]ShotResult, error) {
+	dRow, dCol, err := ParseHeading(heading)
+	if err != nil {
+		return nil, err
+	}
+
+	opponent, row, col, err := g.weaponTarget(p, WeaponTorpedo, cell)
+	if err != nil {
+		return nil, err
+	}
+
+	fresh := false
+	for r, c := row, col; opponent.Board.IsValidPosition(r, c) && !fresh; r, c = r+dRow, c+dCol {
+		fresh = !opponent.Board.AlreadyFired(r, c)
+	}
+	if !fresh {
+		return nil, fmt.Errorf("every cell of a torpedo run from %s: %w", CellName(row, col), ErrAlreadyFired)
+	}
+
+	g.useWeapon(p, WeaponTorpedo)
+
+	torpedo := Event{Kind: EventTorpedo, Player: g.playerNumber(p), Cell: CellName(row, col), Orientation: strings.ToLower(heading)}
+	var results []ShotResult
+	for r, c := row, col; opponent.Board.IsValidPosition(r, c); r, c = r+dRow, c+dCol {
+		res, err := opponent.Board.Fire(r, c)
+		if err != nil {
+			continue // already fired at, the torpedo runs on
+		}
+		results = append(results, res)
+
+		torpedo.Cells = append(torpedo.Cells, CellName(r, c))
+		torpedo.Outcomes = append(torpedo.Outcomes, res.Outcome.String())
+
+		if res.Outcome != Miss {
+			break
+		}
+	}
+	g.record(torpedo)
+
+	g.finishIfOver()
+
+	return results, nil
+}
@@ -0,0 +1,114 @@
+package game
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestWeaponsRunOutOfCharges(t *testing.T) {
+	// each use aims at a fresh row, bob's fleet is on rows 1 to 5
+	tests := []struct {
+		weapon Weapon
+		use    func(g *Game, p *Player, row int) error
+	}{
+		{WeaponRadar, func(g *Game, p *Player, row int) error {
+			_, err := g.Radar(p, CellName(row, 8))
+			return err
+		}},
+		{WeaponStrike, func(g *Game, p *Player, row int) error {
+			_, err := g.Airstrike(p, CellName(row, 8))
+			return err
+		}},
+		{WeaponTorpedo, func(g *Game, p *Player, row int) error {
+			_, err := g.Torpedo(p, CellName(row, 9), "left")
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.weapon), func(t *testing.T) {
+			g, p1, p2 := battle(t, ModeAdvanced)
+
+			charges := DefaultCharges().Left(tt.weapon)
+			for i := 0; i < charges; i++ {
+				if err := tt.use(g, p1, 5+i); err != nil {
+					t.Fatalf("use %d of %d: %v", i+1, charges, err)
+				}
+				g.SkipTurn()
+			}
+
+			if got := g.ChargesFor(p1).Left(tt.weapon); got != 0 {
+				t.Fatalf("%d charges left after using them all", got)
+			}
+
+			if err := tt.use(g, p1, 9); !errors.Is(err, ErrNoCharges) {
+				t.Errorf("firing an empty weapon = %v, want ErrNoCharges", err)
+			}
+			if g.CurrPlayer != 1 {
+				t.Error("an empty weapon cost the turn")
+			}
+
+			// the other side's charges are their own
+			if got, want := g.ChargesFor(p2), DefaultCharges(); got != want {
+				t.Errorf("bob has %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestWeaponsNeedAdvancedRules(t *testing.T) {
+	g, p1, _ := battle(t, ModeClassic)
+
+	if _, err := g.Radar(p1, "E5"); !errors.Is(err, ErrWrongMode) {
+		t.Errorf("radar under classic rules = %v, want ErrWrongMode", err)
+	}
+	if got := g.ChargesFor(p1); got != (Charges{}) {
+		t.Errorf("classic player has charges %+v", got)
+	}
+}
+
+func TestTorpedoNothingNewToHit(t *testing.T) {
+	g, p1, p2 := battle(t, ModeAdvanced)
+
+	// bob's Battleship is on A2-D2, alice clears the water at the far end of its row
+	for _, cell := range []string{"I2", "J2"} {
+		if _, err := g.FireAtOpponent(p1, cell); err != nil {
+			t.Fatal(err)
+		}
+		g.SkipTurn()
+	}
+	events := len(g.History)
+
+	if _, err := g.Torpedo(p1, "I2", "right"); !errors.Is(err, ErrAlreadyFired) {
+		t.Fatalf("a run over fired cells = %v, want ErrAlreadyFired", err)
+	}
+	if got := g.ChargesFor(p1).Left(WeaponTorpedo); got != DefaultCharges().Torpedo {
+		t.Errorf("the refused run spent the charge, %d left", got)
+	}
+	if g.CurrPlayer != 1 || len(g.History) != events {
+		t.Error("the refused run took the turn or was recorded")
+	}
+
+	// the other way the torpedo passes over the fired cells and finds the Battleship
+	results, err := g.Torpedo(p1, "J2", "left")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, res := range results {
+		got = append(got, CellName(res.Row, res.Col)+" "+res.Outcome.String())
+	}
+	want := []string{"H2 MISS", "G2 MISS", "F2 MISS", "E2 MISS", "D2 HIT"}
+	if !slices.Equal(got, want) {
+		t.Errorf("torpedo run %v, want %v", got, want)
+	}
+
+	if got := g.ChargesFor(p1).Left(WeaponTorpedo); got != DefaultCharges().Torpedo-1 {
+		t.Errorf("%d torpedoes left, want %d", got, DefaultCharges().Torpedo-1)
+	}
+	if p2.Board.Ships[1].Hits != 1 {
+		t.Errorf("Battleship took %d hits, want 1", p2.Board.Ships[1].Hits)
+	}
+}
@@ -0,0 +1,174 @@
+package protocol
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// upper bound for a single line, a 26x26 game state fits comfortably
+const MaxMessageSize = 1 << 20
+
+// ErrMalformed wraps decode errors that only spoil one line, the stream can keep going
+var ErrMalformed = errors.New("malformed message")
+
+type envelope struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+// Marshal encodes m as a single newline-terminated line
+func Marshal(m Message) ([]byte, error) {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	line, err := json.Marshal(envelope{Type: m.MessageType(), Data: data})
+	if err != nil {
+		return nil, err
+	}
+
+	return append(line, '\n'), nil
+}
+
+// Unmarshal decodes one line produced by Marshal into its concrete message type
+func Unmarshal(line []byte) (Message, error) {
+	var env envelope
+	if err := json.Unmarshal(line, &env); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+
+	var m Message
+	switch env.Type {
+	case TypeHello:
+		m = &Hello{}
+	case TypeWelcome:
+		m = &Welcome{}
+	case TypeCommand:
+		m = &Command{}
+	case TypeNotice:
+		m = &Notice{}
+	case TypeError:
+		m = &Error{}
+	case TypeEffect:
+		m = &Effect{}
+	case TypeState:
+		m = &State{}
+	case TypeSession:
+		m = &Session{}
+	case TypeChat:
+		m = &Chat{}
+	default:
+		return nil, fmt.Errorf("%w: unknown type %q", ErrMalformed, env.Type)
+	}
+
+	if len(env.Data) > 0 {
+		if err := json.Unmarshal(env.Data, m); err != nil {
+			return nil, fmt.Errorf("%w: bad %s data: %v", ErrMalformed, env.Type, err)
+		}
+	}
+
+	return deref(m), nil
+}
+
+// deref hands messages back by value so callers can type switch on Notice, not *Notice
+func deref(m Message) Message {
+	switch v := m.(type) {
+	case *Hello:
+		return *v
+	case *Welcome:
+		return *v
+	case *Command:
+		return *v
+	case *Notice:
+		return *v
+	case *Error:
+		return *v
+	case *Effect:
+		return *v
+	case *State:
+		return *v
+	case *Session:
+		return *v
+	case *Chat:
+		return *v
+	}
+
+	return m
+}
+
+type Encoder struct {
+	w io.Writer
+}
+
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+func (e *Encoder) Encode(m Message) error {
+	line, err := Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	_, err = e.w.Write(line)
+	return err
+}
+
+// Decoder reads newline-delimited messages, however the bytes were split or coalesced on the wire
+type Decoder struct {
+	scanner *bufio.Scanner
+}
+
+func NewDecoder(r io.Reader) *Decoder {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 4096), MaxMessageSize)
+
+	return &Decoder{scanner: scanner}
+}
+
+// Decode returns the next message, io.EOF once the stream ends cleanly
+func (d *Decoder) Decode() (Message, error) {
+	for d.scanner.Scan() {
+		line := d.scanner.Bytes()
+		if len(line) == 0 {
+			continue // tolerate blank keep-alive lines
+		}
+
+		return Unmarshal(line)
+	}
+
+	if err := d.scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return nil, fmt.Errorf("message exceeds %d bytes", MaxMessageSize)
+		}
+		return nil, err
+	}
+
+	return nil, io.EOF
+}
+
+// ClientHandshake sends Hello for every version this build speaks and waits for the server's answer.
+// A non-empty token asks the server to resume that session once the handshake is done.
+func ClientHandshake(enc *Encoder, dec *Decoder, token string) (Welcome, error) {
+	if err := enc.Encode(Hello{MinVersion: MinVersion, MaxVersion: Version, Token: token}); err != nil {
+		return Welcome{}, err
+	}
+
+	m, err := dec.Decode()
+	if err != nil {
+		return Welcome{}, err
+	}
+
+	switch reply := m.(type) {
+	case Welcome:
+		return reply, nil
+	case Error:
+		return Welcome{}, reply
+	default:
+		return Welcome{}, fmt.Errorf("unexpected %s message during handshake", m.MessageType())
+	}
+}
@@ -0,0 +1,138 @@
+package protocol
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestDecodeSplitAndCoalescedLines(t *testing.T) {
+	var wire bytes.Buffer
+	enc := NewEncoder(&wire)
+
+	sent := []Message{
+		Command{Name: "/fire", Args: []string{"B3"}},
+		Notice{Tag: "SHOT_RESULT", Text: "Hit!"},
+		Chat{From: "ann", Text: "gg", Channel: ChannelLobby},
+	}
+	for i, m := range sent {
+		if err := enc.Encode(m); err != nil {
+			t.Fatal(err)
+		}
+		if i == 0 {
+			wire.WriteString("\n") // a blank keep-alive line
+		}
+	}
+
+	// a byte per read splits every line, a single read would have coalesced them
+	for name, r := range map[string]io.Reader{
+		"split":     iotest.OneByteReader(bytes.NewReader(wire.Bytes())),
+		"coalesced": bytes.NewReader(wire.Bytes()),
+	} {
+		dec := NewDecoder(r)
+		for _, want := range sent {
+			got, err := dec.Decode()
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: decoded %#v, want %#v", name, got, want)
+			}
+		}
+
+		if _, err := dec.Decode(); err != io.EOF {
+			t.Errorf("%s: after the last message got %v, want io.EOF", name, err)
+		}
+	}
+}
+
+func TestDecodeMalformedLineKeepsStream(t *testing.T) {
+	dec := NewDecoder(strings.NewReader("not json\n" +
+		`{"type":"bogus"}` + "\n" +
+		`{"type":"notice","data":{"tag":"OK"}}` + "\n"))
+
+	for i := 0; i < 2; i++ {
+		if _, err := dec.Decode(); !errors.Is(err, ErrMalformed) {
+			t.Fatalf("line %d: got %v, want ErrMalformed", i+1, err)
+		}
+	}
+
+	m, err := dec.Decode()
+	if err != nil || m != (Notice{Tag: "OK"}) {
+		t.Errorf("after the bad lines got %#v, %v", m, err)
+	}
+}
+
+func TestDecodeOversizedLine(t *testing.T) {
+	line := `{"type":"notice","data":{"text":"` + strings.Repeat("x", MaxMessageSize) + `"}}` + "\n"
+	dec := NewDecoder(strings.NewReader(line))
+
+	_, err := dec.Decode()
+	if err == nil || errors.Is(err, ErrMalformed) || errors.Is(err, io.EOF) {
+		t.Fatalf("got %v, want an error ending the stream", err)
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		hello   Hello
+		version int
+		ok      bool
+	}{
+		{Hello{MinVersion: MinVersion, MaxVersion: Version}, Version, true},
+		{Hello{MinVersion: 1, MaxVersion: Version + 5}, Version, true}, // a newer client
+		{Hello{MinVersion: 1, MaxVersion: MinVersion}, MinVersion, true},
+		{Hello{MinVersion: 1, MaxVersion: MinVersion - 1}, 0, false}, // too old
+		{Hello{MinVersion: Version + 1, MaxVersion: Version + 2}, 0, false},
+	}
+
+	for _, tt := range tests {
+		version, ok := Negotiate(tt.hello)
+		if version != tt.version || ok != tt.ok {
+			t.Errorf("Negotiate(%+v) = %d, %v, want %d, %v", tt.hello, version, ok, tt.version, tt.ok)
+		}
+	}
+}
+
+func TestClientHandshake(t *testing.T) {
+	tests := []struct {
+		name  string
+		reply Message
+		err   bool
+	}{
+		{"welcome", Welcome{Version: Version}, false},
+		{"version mismatch", Error{Code: CodeUnsupportedVersion, Text: "server speaks protocol 5 to 6"}, true},
+		{"out of turn", Notice{Tag: "NAME_SET"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sent, replies bytes.Buffer
+			if err := NewEncoder(&replies).Encode(tt.reply); err != nil {
+				t.Fatal(err)
+			}
+
+			welcome, err := ClientHandshake(NewEncoder(&sent), NewDecoder(&replies), "tok")
+			if (err != nil) != tt.err {
+				t.Fatalf("ClientHandshake error %v, want error %v", err, tt.err)
+			}
+
+			var serverErr Error
+			if errors.As(err, &serverErr) && serverErr.Code != CodeUnsupportedVersion {
+				t.Errorf("got code %q, want %q", serverErr.Code, CodeUnsupportedVersion)
+			}
+			if !tt.err && welcome.Version != Version {
+				t.Errorf("welcomed at version %d, want %d", welcome.Version, Version)
+			}
+
+			hello, err := NewDecoder(&sent).Decode()
+			if err != nil || hello != (Hello{MinVersion: MinVersion, MaxVersion: Version, Token: "tok"}) {
+				t.Errorf("sent %#v, %v", hello, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package protocol
+
+import (
+	"strings"
+
+	"github.com/ahmaruff/go-fleet/internal/game"
+)
+
+// Every message travels as one JSON object per line:
+//
+//	{"type":"command","data":{"name":"/fire","args":["B3"]}}
+//
+// A connection starts with a handshake: the client sends Hello with the range
+// of versions it speaks, the server answers Welcome with the version both
+// sides will use, or an Error with CodeUnsupportedVersion and hangs up.
+
+// version history:
+//
+//	1 - boards sent as pre-rendered ANSI text (display)
+//	2 - boards sent as structured, fog-of-war filtered snapshots (state)
+//	3 - session tokens, a client may resume its match after a dropped connection
+//	4 - chat messages, older clients get chat as CHAT notices
+const (
+	Version    = 4 // newest version this build speaks
+	MinVersion = 2 // oldest version this build still accepts
+)
+
+const (
+	TypeHello   = "hello"
+	TypeWelcome = "welcome"
+	TypeCommand = "command"
+	TypeNotice  = "notice"
+	TypeError   = "error"
+	TypeEffect  = "effect"
+	TypeState   = "state"
+	TypeSession = "session"
+	TypeChat    = "chat"
+)
+
+// error codes sent in Error.Code
+const (
+	CodeBadRequest         = "bad_request"
+	CodeUnsupportedVersion = "unsupported_version"
+	CodeUnknownCommand     = "unknown_command"
+	CodeUsage              = "usage"
+	CodeNotInGame          = "not_in_game"
+	CodeWrongPhase         = "wrong_phase"
+	CodeNotYourTurn        = "not_your_turn"
+	CodeInvalidMove        = "invalid_move"
+	CodeNameRequired       = "name_required"
+	CodeAlreadyNamed       = "already_named"
+	CodeAlreadyInGame      = "already_in_game"
+	CodeSessionExpired     = "session_expired"
+	CodeSpectating         = "spectating"
+	CodeNoSuchGame         = "no_such_game"
+	CodeInRoom             = "in_room"
+	CodeNotInRoom          = "not_in_room"
+	CodeNoSuchRoom         = "no_such_room"
+	CodeWrongPassword      = "wrong_password"
+	CodeNotHost            = "not_host"
+	CodeAccountsDisabled   = "accounts_disabled"
+	CodeNameTaken          = "name_taken"
+	CodeBadCredentials     = "bad_credentials"
+	CodeNoSuchPlayer       = "no_such_player"
+	CodeNotQueued          = "not_queued"
+	CodeNoCharges          = "no_charges"
+	CodeAlreadyFired       = "already_fired"
+	CodeOutOfBounds        = "out_of_bounds"
+	CodeFleetComplete      = "fleet_complete"
+	CodeWrongMode          = "wrong_mode"
+	CodeFleetIncomplete    = "fleet_incomplete"
+	CodeLockedIn           = "locked_in"
+	CodeRateLimited        = "rate_limited"
+	CodeTooLong            = "too_long"
+	CodeNoRematch          = "no_rematch"
+	CodeNotAdmin           = "not_admin"
+	CodeNoTournament       = "no_tournament"
+)
+
+// chat channels sent in Chat.Channel
+const (
+	ChannelGame  = "game"  // the players of a match and everyone watching it
+	ChannelLobby = "lobby" // everyone who isn't in or watching a match
+
+	ChannelSpectators = "spectators" // a match's spectators among themselves, the players don't hear it
+)
+
+type Message interface {
+	MessageType() string
+}
+
+// client -> server, first message on every connection.
+// Token resumes an earlier session, leave it empty to start fresh.
+type Hello struct {
+	MinVersion int    `json:"min_version"`
+	MaxVersion int    `json:"max_version"`
+	Token      string `json:"token,omitempty"`
+}
+
+// server -> client, handshake accepted
+type Welcome struct {
+	Version int `json:"version"`
+}
+
+// client -> server, one slash command such as /fire B3
+type Command struct {
+	Name string   `json:"name"`
+	Args []string `json:"args,omitempty"`
+}
+
+// server -> client, a tagged status line such as [SHOT_RESULT] - HIT at B3
+type Notice struct {
+	Tag  string `json:"tag"`
+	Text string `json:"text,omitempty"`
+}
+
+// server -> client, a rejected command or handshake
+type Error struct {
+	Code string `json:"code"`
+	Text string `json:"text"`
+}
+
+// server -> client, name of an ASCII art effect from the effects package
+type Effect struct {
+	Name string `json:"name"`
+}
+
+// server -> client, keep Token to resume after a dropped connection
+type Session struct {
+	Token        string `json:"token"`
+	Name         string `json:"name"`
+	GraceSeconds int    `json:"grace_seconds"`        // how long a match is held after a disconnect
+	Registered   bool   `json:"registered,omitempty"` // logged in to an account rather than a guest
+}
+
+// server -> client, this player's view of the game, rendered locally by the client
+type State struct {
+	View game.View `json:"view"`
+}
+
+// server -> client, a line someone said with /say
+type Chat struct {
+	Channel string `json:"channel"`
+	From    string `json:"from"`
+	Text    string `json:"text"`
+}
+
+func (Hello) MessageType() string   { return TypeHello }
+func (Welcome) MessageType() string { return TypeWelcome }
+func (Command) MessageType() string { return TypeCommand }
+func (Notice) MessageType() string  { return TypeNotice }
+func (Error) MessageType() string   { return TypeError }
+func (Effect) MessageType() string  { return TypeEffect }
+func (State) MessageType() string   { return TypeState }
+func (Session) MessageType() string { return TypeSession }
+func (Chat) MessageType() string    { return TypeChat }
+
+func (e Error) Error() string {
+	return e.Code + ": " + e.Text
+}
+
+// Negotiate picks the newest version both sides speak, ok is false when the ranges don't overlap
+func Negotiate(h Hello) (int, bool) {
+	version := min(h.MaxVersion, Version)
+	if version < max(h.MinVersion, MinVersion) {
+		return 0, false
+	}
+
+	return version, true
+}
+
+// ParseCommand splits a typed line such as "/fire b3" into a Command
+func ParseCommand(line string) (Command, bool) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return Command{}, false
+	}
+
+	return Command{Name: fields[0], Args: fields[1:]}, true
+}
@@ -0,0 +1,23 @@
+package rating
+
+import "math"
+
+const (
+	Initial = 1200 // every new account starts here
+	K       = 32   // the most a single match can move a rating
+)
+
+// Expected is the chance the player rated a beats the player rated b
+func Expected(a, b int) float64 {
+	return 1 / (1 + math.Pow(10, float64(b-a)/400))
+}
+
+// Update returns the new ratings after winner beat loser
+func Update(winner, loser int) (int, int) {
+	change := int(math.Round(K * (1 - Expected(winner, loser))))
+
+	// even a heavy favourite gains something for winning
+	change = max(change, 1)
+
+	return winner + change, loser - change
+}
@@ -0,0 +1,100 @@
+// Package replay stores finished matches as JSON lines, one game.Event per line
+package replay
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ahmaruff/go-fleet/internal/game"
+)
+
+// FileName names a replay after when the match ended and who played it,
+// e.g. 20250101-150405-alice-vs-bob.jsonl
+func FileName(g *game.Game, ended time.Time) string {
+	return fmt.Sprintf("%s-%s-vs-%s.jsonl", ended.Format("20060102-150405"), slug(g.Player1.Name), slug(g.Player2.Name))
+}
+
+// slug keeps player names safe to use in a file name
+func slug(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case b.Len() > 0 && !strings.HasSuffix(b.String(), "_"):
+			b.WriteRune('_')
+		}
+	}
+
+	s := strings.Trim(b.String(), "_")
+	if s == "" {
+		return "player"
+	}
+	return s
+}
+
+func Write(w io.Writer, events []game.Event) error {
+	encoder := json.NewEncoder(w)
+	for _, e := range events {
+		if err := encoder.Encode(e); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Save writes the events to dir/name, creating dir if needed
+func Save(dir, name string, events []game.Event) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, name)
+	file, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+
+	if err := Write(file, events); err != nil {
+		file.Close()
+		return "", err
+	}
+
+	return path, file.Close()
+}
+
+func Read(r io.Reader) ([]game.Event, error) {
+	var events []game.Event
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		var e game.Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		events = append(events, e)
+	}
+
+	return events, scanner.Err()
+}
+
+func Load(path string) ([]game.Event, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return Read(file)
+}
@@ -0,0 +1,138 @@
+package replay
+
+import (
+	"bytes"
+	"math/rand/v2"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ahmaruff/go-fleet/internal/game"
+)
+
+// unfired lists the first n cells of b nobody has fired at yet, row by row
+func unfired(b *game.Board, n int) []string {
+	var cells []string
+	for row := 0; row < b.Height && len(cells) < n; row++ {
+		for col := 0; col < b.Width && len(cells) < n; col++ {
+			if !b.AlreadyFired(row, col) {
+				cells = append(cells, game.CellName(row, col))
+			}
+		}
+	}
+	return cells
+}
+
+// playMatch plays a whole match under mode, touching every kind of event the mode has
+func playMatch(t *testing.T, mode game.Mode) *game.Game {
+	t.Helper()
+
+	rules := game.DefaultRules()
+	rules.Mode = mode
+
+	players := [2]*game.Player{{Name: "alice"}, {Name: "bob"}}
+	g := game.NewGameWithRules(players[0], players[1], rules)
+	r := rand.New(rand.NewPCG(1, 2))
+
+	// a ship placed, taken back and placed again
+	if err := g.PlaceShipForPlayer(players[0], "Carrier", "A1", "H"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.RemoveShipForPlayer(players[0], "Carrier"); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range players {
+		if err := g.PlaceRandomFleetForPlayer(p, r); err != nil {
+			t.Fatal(err)
+		}
+		if err := g.ConfirmFleet(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for turn := 0; ; turn++ {
+		if _, over := g.IsGameOver(); over {
+			return g
+		}
+
+		p := players[g.CurrPlayer-1]
+		opponent := players[2-g.CurrPlayer]
+
+		var err error
+		switch {
+		case turn == 3:
+			g.SkipTurn()
+		case mode == game.ModeSalvo:
+			_, err = g.FireSalvo(p, unfired(opponent.Board, g.ShotsAllowed(p)))
+		case mode == game.ModeAdvanced && turn < 2:
+			_, err = g.Radar(p, "E5")
+		case mode == game.ModeAdvanced && turn < 4:
+			_, err = g.Airstrike(p, "H8")
+		case mode == game.ModeAdvanced && turn < 6:
+			_, err = g.Torpedo(p, "J1", "N")
+		default:
+			_, err = g.FireAtOpponent(p, unfired(opponent.Board, 1)[0])
+		}
+		if err != nil {
+			t.Fatalf("turn %d: %v", turn, err)
+		}
+	}
+}
+
+func TestReplayRebuildsGame(t *testing.T) {
+	for _, mode := range []game.Mode{game.ModeClassic, game.ModeSalvo, game.ModeAdvanced} {
+		t.Run(string(mode), func(t *testing.T) {
+			g := playMatch(t, mode)
+
+			var buf bytes.Buffer
+			if err := Write(&buf, g.History); err != nil {
+				t.Fatal(err)
+			}
+
+			events, err := Read(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			replayed, err := game.NewGameFromHistory(events[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, e := range events[1:] {
+				if err := replayed.Apply(e); err != nil {
+					t.Fatalf("Apply(%+v): %v", e, err)
+				}
+			}
+
+			if got, want := replayed.RevealedView(), g.RevealedView(); !reflect.DeepEqual(got, want) {
+				t.Errorf("replayed board\n%+v\nwant\n%+v", got, want)
+			}
+
+			winner, _ := g.IsGameOver()
+			if got, over := replayed.IsGameOver(); !over || got != winner {
+				t.Errorf("replay ends with winner %d (over %v), want %d", got, over, winner)
+			}
+
+			// the replay records the same match over again, only the clock differs
+			if len(replayed.History) != len(g.History) {
+				t.Fatalf("replay recorded %d events, want %d", len(replayed.History), len(g.History))
+			}
+			for i, e := range replayed.History {
+				want := g.History[i]
+				e.Time, want.Time = time.Time{}, time.Time{}
+				if !reflect.DeepEqual(e, want) {
+					t.Errorf("event %d is %+v, want %+v", i+1, e, want)
+				}
+			}
+		})
+	}
+}
+
+func TestFileName(t *testing.T) {
+	g := game.NewGame(&game.Player{Name: "Alice B."}, &game.Player{Name: "../.."})
+	ended := time.Date(2025, 1, 1, 15, 4, 5, 0, time.UTC)
+
+	if got, want := FileName(g, ended), "20250101-150405-alice_b-vs-player.jsonl"; got != want {
+		t.Errorf("FileName = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,154 @@
+package server
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/ahmaruff/go-fleet/internal/protocol"
+	"github.com/ahmaruff/go-fleet/internal/store"
+)
+
+const (
+	minAccountName = 3
+	maxAccountName = 20
+	minPassword    = 6
+)
+
+func validateAccountName(name string) error {
+	if len(name) < minAccountName || len(name) > maxAccountName {
+		return errors.New("names must be 3 to 20 characters long")
+	}
+
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-':
+		default:
+			return errors.New("names may only use letters, digits, _ and -")
+		}
+	}
+
+	return nil
+}
+
+// nameInUse reports whether any player of that name, guest or registered, is
+// online or holding a match
+func (h *hub) nameInUse(name string) bool {
+	for _, sess := range h.sessions {
+		if strings.EqualFold(sess.player.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// handleRegister creates an account and logs in to it, /register <name> <password>
+func (h *hub) handleRegister(c *client, args []string) protocol.Message {
+	if h.config.Store == nil {
+		return errorMsg(protocol.CodeAccountsDisabled, "Accounts are disabled on this server, use /name")
+	}
+
+	if len(args) != 2 {
+		return errorMsg(protocol.CodeUsage, "Usage: /register <name> <password>")
+	}
+
+	if _, exists := h.clients[c]; exists {
+		return errorMsg(protocol.CodeAlreadyNamed, "You already have a name set. You can't change it.")
+	}
+
+	name, password := args[0], args[1]
+
+	if err := validateAccountName(name); err != nil {
+		return errorMsg(protocol.CodeUsage, "Cannot register "+name+": "+err.Error())
+	}
+
+	if len(password) < minPassword {
+		return errorMsg(protocol.CodeUsage, "Passwords need at least 6 characters")
+	}
+
+	if h.nameInUse(name) {
+		return errorMsg(protocol.CodeNameTaken, name+" is already taken")
+	}
+
+	// hashing is slow on purpose, keep it off the hub goroutine
+	go func() {
+		account, err := store.NewAccount(name, password)
+		if err == nil {
+			err = h.config.Store.CreateAccount(account)
+		}
+
+		h.post(event{kind: eventFunc, fn: func() {
+			switch {
+			case errors.Is(err, store.ErrExists):
+				c.send(errorMsg(protocol.CodeNameTaken, name+" is already taken"))
+			case err != nil:
+				h.logf("[SERVER] Failed to create account %s: %v\n", name, err)
+				c.send(errorMsg(protocol.CodeBadRequest, "Could not create your account, try again later"))
+			default:
+				h.login(c, account, "REGISTERED", "Account created, welcome "+account.Name+"!")
+			}
+		}})
+	}()
+
+	return nil
+}
+
+// handleLogin resumes a registered name, /login <name> <password>
+func (h *hub) handleLogin(c *client, args []string) protocol.Message {
+	if h.config.Store == nil {
+		return errorMsg(protocol.CodeAccountsDisabled, "Accounts are disabled on this server, use /name")
+	}
+
+	if len(args) != 2 {
+		return errorMsg(protocol.CodeUsage, "Usage: /login <name> <password>")
+	}
+
+	if _, exists := h.clients[c]; exists {
+		return errorMsg(protocol.CodeAlreadyNamed, "You already have a name set. You can't change it.")
+	}
+
+	name, password := args[0], args[1]
+
+	go func() {
+		account, err := h.config.Store.Account(name)
+		if err != nil {
+			// an unknown name pays for a hash just like a wrong password
+			account = store.NoAccount
+		}
+		ok := account.CheckPassword(password) && err == nil
+
+		h.post(event{kind: eventFunc, fn: func() {
+			if !ok {
+				// same answer for unknown names and wrong passwords
+				c.send(errorMsg(protocol.CodeBadCredentials, "Wrong name or password"))
+				return
+			}
+
+			h.login(c, account, "LOGGED_IN", "Welcome back "+account.Name+"!")
+		}})
+	}()
+
+	return nil
+}
+
+// login starts a registered session once the password checked out, things may
+// have changed on the hub while the hash was being computed
+func (h *hub) login(c *client, account store.Account, tag, text string) {
+	name := account.Name
+
+	if c.closed {
+		return
+	}
+
+	if _, exists := h.clients[c]; exists {
+		c.send(errorMsg(protocol.CodeAlreadyNamed, "You already have a name set. You can't change it."))
+		return
+	}
+
+	if h.nameInUse(name) {
+		c.send(errorMsg(protocol.CodeNameTaken, name+" is already in use"))
+		return
+	}
+
+	h.newSession(c, name, true, account.Stats.Rating)
+	c.send(notice(tag, text))
+}
@@ -0,0 +1,39 @@
+package server
+
+import (
+	"time"
+
+	"github.com/ahmaruff/go-fleet/internal/ai"
+	"github.com/ahmaruff/go-fleet/internal/game"
+)
+
+// pause before a bot fires, so its shot doesn't land in the same frame as yours
+const botDelay = 700 * time.Millisecond
+
+func (h *hub) newBot(level ai.Level) *session {
+	return &session{
+		player: &game.Player{Name: "Computer (" + string(level) + ")"},
+		bot:    ai.New(level, h.rng),
+	}
+}
+
+// scheduleBot lets a bot take its shot when the turn passes to it
+func (h *hub) scheduleBot(m *match) {
+	g := m.game
+	if g.Phase != game.PhasePlaying {
+		return
+	}
+
+	mover := m.players[g.CurrPlayer-1]
+	if mover.bot == nil {
+		return
+	}
+
+	h.after(botDelay, func() {
+		if h.matches[m.id] != m || g.Phase != game.PhasePlaying || m.players[g.CurrPlayer-1] != mover {
+			return // match ended or moved on while we waited
+		}
+
+		h.handleFire(mover, h.pickShots(m, mover, mover.bot))
+	})
+}
@@ -0,0 +1,162 @@
+package server
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ahmaruff/go-fleet/internal/protocol"
+)
+
+const (
+	maxChatLength = 200             // runes per message
+	chatBurst     = 5               // messages a connection may send back to back
+	chatRefill    = 2 * time.Second // earns back one message of the burst
+)
+
+// handleSay sends a line to the match you're playing, to your fellow spectators
+// of the match you're watching, or to the lobby when you're in neither, /say <message>
+func (h *hub) handleSay(sess *session, args []string) protocol.Message {
+	text := cleanText(strings.Join(args, " "))
+	if text == "" {
+		return errorMsg(protocol.CodeUsage, "Usage: /say <message>")
+	}
+
+	if utf8.RuneCountInString(text) > maxChatLength {
+		return errorMsg(protocol.CodeTooLong, fmt.Sprintf("Messages are limited to %d characters", maxChatLength))
+	}
+
+	if wait, ok := sess.client.allowChat(time.Now()); !ok {
+		return errorMsg(protocol.CodeRateLimited, fmt.Sprintf("Slow down, you can chat again in %s", (wait+time.Second-1).Truncate(time.Second)))
+	}
+
+	channel, recipients := h.chatRecipients(sess)
+	msg := protocol.Chat{Channel: channel, From: sess.player.Name, Text: text}
+
+	for _, recipient := range recipients {
+		if recipient != sess && !recipient.ignores(sess.player.Name) {
+			recipient.chat(msg)
+		}
+	}
+
+	// your own line comes back to you, so the client shows it in order
+	sess.chat(msg)
+	return nil
+}
+
+// chatRecipients is everyone who hears sess: the match it plays, the other
+// spectators of the match it watches, otherwise every named player outside a match.
+// Spectators never talk to the players, they may be able to see both fleets.
+func (h *hub) chatRecipients(sess *session) (string, []*session) {
+	if m := h.findMatch(sess); m != nil {
+		return protocol.ChannelGame, append([]*session{m.players[0], m.players[1]}, m.spectators...)
+	}
+
+	if m := sess.watching; m != nil {
+		return protocol.ChannelSpectators, m.spectators
+	}
+
+	busy := make(map[*session]bool)
+	for _, m := range h.matches {
+		busy[m.players[0]] = true
+		busy[m.players[1]] = true
+	}
+
+	var lobby []*session
+	for _, other := range h.clients {
+		if !busy[other] && other.watching == nil {
+			lobby = append(lobby, other)
+		}
+	}
+
+	return protocol.ChannelLobby, lobby
+}
+
+// handleIgnore drops chat from a player for the rest of the session,
+// /ignore <name>, or lists who's ignored without a name
+func (h *hub) handleIgnore(sess *session, args []string) protocol.Message {
+	if len(args) < 1 {
+		if len(sess.ignoring) == 0 {
+			return notice("IGNORING", "You're not ignoring anyone, /ignore <name> to stop seeing someone's chat")
+		}
+
+		names := make([]string, 0, len(sess.ignoring))
+		for _, name := range sess.ignoring {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+
+		return notice("IGNORING", "Ignoring "+strings.Join(names, ", ")+", /unignore <name> to undo")
+	}
+
+	name := strings.Join(args, " ")
+	if strings.EqualFold(name, sess.player.Name) {
+		return errorMsg(protocol.CodeUsage, "You can't ignore yourself")
+	}
+
+	if sess.ignoring == nil {
+		sess.ignoring = make(map[string]string)
+	}
+	sess.ignoring[strings.ToLower(name)] = name
+
+	return notice("IGNORING", "You won't see chat from "+name+", /unignore "+name+" to undo")
+}
+
+// handleUnignore lets a player's chat through again, /unignore <name>
+func (h *hub) handleUnignore(sess *session, args []string) protocol.Message {
+	if len(args) < 1 {
+		return errorMsg(protocol.CodeUsage, "Usage: /unignore <name>")
+	}
+
+	name := strings.Join(args, " ")
+	if !sess.ignores(name) {
+		return errorMsg(protocol.CodeNoSuchPlayer, "You're not ignoring "+name)
+	}
+
+	delete(sess.ignoring, strings.ToLower(name))
+
+	return notice("IGNORING", "You'll see chat from "+name+" again")
+}
+
+func (s *session) ignores(name string) bool {
+	_, ok := s.ignoring[strings.ToLower(name)]
+	return ok
+}
+
+// chat sends a chat line, clients from before protocol 4 get it as a CHAT notice
+func (s *session) chat(msg protocol.Chat) {
+	if s.client != nil && s.client.version < 4 {
+		s.notice("CHAT", fmt.Sprintf("[%s] %s: %s", msg.Channel, msg.From, msg.Text))
+		return
+	}
+
+	s.send(msg)
+}
+
+// allowChat spends one message of the connection's burst, refilled one every
+// chatRefill. When it's empty, wait is how long until the next message is allowed.
+func (c *client) allowChat(now time.Time) (wait time.Duration, ok bool) {
+	c.chatAllowance = min(chatBurst, c.chatAllowance+float64(now.Sub(c.chatAt))/float64(chatRefill))
+	c.chatAt = now
+
+	if c.chatAllowance < 1 {
+		return time.Duration((1 - c.chatAllowance) * float64(chatRefill)), false
+	}
+
+	c.chatAllowance--
+	return 0, true
+}
+
+// cleanText drops control and formatting characters from anything one player
+// shows others, so nobody can clear or recolour another player's screen
+func cleanText(text string) string {
+	return strings.TrimSpace(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || unicode.Is(unicode.Cf, r) {
+			return -1
+		}
+		return r
+	}, text))
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"net"
+	"time"
+
+	"github.com/ahmaruff/go-fleet/internal/protocol"
+)
+
+// how many pending messages a client may lag behind before it's dropped
+const clientBacklog = 256
+
+type client struct {
+	conn    net.Conn
+	out     chan []byte
+	version int // negotiated protocol version
+
+	// owned by the hub goroutine
+	closed        bool
+	chatAllowance float64   // messages left in the chat burst, see allowChat
+	chatAt        time.Time // when chatAllowance was last topped up
+}
+
+func newClient(conn net.Conn, version int) *client {
+	return &client{
+		conn:    conn,
+		out:     make(chan []byte, clientBacklog),
+		version: version,
+	}
+}
+
+// writeLoop is the only goroutine writing to the connection
+func (c *client) writeLoop() {
+	for line := range c.out {
+		if _, err := c.conn.Write(line); err != nil {
+			c.conn.Close()
+		}
+	}
+}
+
+// send queues m without blocking the hub, a client that can't keep up is disconnected
+func (c *client) send(m protocol.Message) {
+	if c.closed || m == nil {
+		return
+	}
+
+	line, err := protocol.Marshal(m)
+	if err != nil {
+		return
+	}
+
+	select {
+	case c.out <- line:
+	default:
+		c.conn.Close()
+	}
+}
+
+// close stops the writer once everything queued so far has been flushed
+func (c *client) close() {
+	if c.closed {
+		return
+	}
+
+	c.closed = true
+	close(c.out)
+}
+
+func notice(tag, text string) protocol.Message {
+	return protocol.Notice{Tag: tag, Text: text}
+}
+
+func errorMsg(code, text string) protocol.Message {
+	return protocol.Error{Code: code, Text: text}
+}
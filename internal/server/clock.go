@@ -0,0 +1,152 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ahmaruff/go-fleet/internal/ai"
+	"github.com/ahmaruff/go-fleet/internal/game"
+)
+
+// TimeoutPolicy is what happens when a turn runs out
+type TimeoutPolicy string
+
+const (
+	TimeoutSkip    TimeoutPolicy = "skip"    // the turn passes to the opponent
+	TimeoutRandom  TimeoutPolicy = "random"  // a random shot is fired for the player
+	TimeoutForfeit TimeoutPolicy = "forfeit" // turns are skipped until ForfeitAfter timeouts lose the match
+)
+
+func ParseTimeoutPolicy(s string) (TimeoutPolicy, error) {
+	switch policy := TimeoutPolicy(strings.ToLower(strings.TrimSpace(s))); policy {
+	case TimeoutSkip, TimeoutRandom, TimeoutForfeit:
+		return policy, nil
+	default:
+		return "", errors.New("unknown timeout policy: expected skip, random or forfeit")
+	}
+}
+
+// players on the clock are warned when this much time is left
+var timeWarnings = []time.Duration{10 * time.Second, 5 * time.Second}
+
+// startClock sets the deadline for whatever the match waits on now, the
+// fleets during placement or the current player's shot during combat
+func (h *hub) startClock(m *match) {
+	m.stopClock()
+
+	limit := h.config.TurnTime
+	if m.game.Phase.Placing() {
+		limit = h.config.PlacementTime
+	}
+
+	if limit <= 0 || m.game.Phase.Over() {
+		m.game.Deadline = time.Time{}
+		return
+	}
+
+	deadline := time.Now().Add(limit)
+	m.game.Deadline = deadline
+
+	// a stopped timer may already have posted its event, the deadline tells stale ones apart
+	current := func() bool {
+		return h.matches[m.id] == m && m.game.Deadline.Equal(deadline)
+	}
+
+	for _, left := range timeWarnings {
+		if left >= limit {
+			continue
+		}
+
+		m.clock = append(m.clock, h.after(limit-left, func() {
+			if current() {
+				h.warn(m, left)
+			}
+		}))
+	}
+
+	m.clock = append(m.clock, h.after(limit, func() {
+		if current() {
+			h.expire(m)
+		}
+	}))
+}
+
+func (m *match) stopClock() {
+	for _, timer := range m.clock {
+		timer.Stop()
+	}
+	m.clock = nil
+}
+
+func (h *hub) warn(m *match, left time.Duration) {
+	seconds := int(left / time.Second)
+
+	if m.game.Phase.Placing() {
+		for _, sess := range m.players {
+			if !m.game.Confirmed(sess.player) {
+				sess.notice("TIME_WARNING", fmt.Sprintf("%d seconds left to place your fleet!", seconds))
+			}
+		}
+		return
+	}
+
+	m.players[m.game.CurrPlayer-1].notice("TIME_WARNING", fmt.Sprintf("%d seconds left to fire!", seconds))
+}
+
+// expire applies the timeout policy to whoever let the deadline pass
+func (h *hub) expire(m *match) {
+	g := m.game
+
+	switch g.Phase {
+	case game.PhasePlacing, game.PhaseReadyCheck:
+		for i, sess := range m.players {
+			if g.Confirmed(sess.player) {
+				continue
+			}
+
+			if h.timedOut(m, i) {
+				return
+			}
+
+			g.PlaceRandomFleetForPlayer(sess.player, h.rng)
+			g.ConfirmFleet(sess.player)
+			sess.notice("TIMEOUT", "Time's up! Your fleet was completed at random and locked in")
+			sess.state(h.viewFor(m, sess))
+		}
+
+	case game.PhasePlaying:
+		i := g.CurrPlayer - 1
+		sess := m.players[i]
+
+		if h.timedOut(m, i) {
+			return
+		}
+
+		if h.config.TimeoutPolicy == TimeoutRandom {
+			sess.notice("TIMEOUT", "Time's up! Firing at random")
+			sess.send(h.handleFire(sess, h.pickShots(m, sess, ai.New(ai.Easy, h.rng))))
+			return
+		}
+
+		m.notice("TIMEOUT", sess.player.Name+" ran out of time, turn skipped")
+		g.SkipTurn()
+	}
+}
+
+// timedOut counts a timeout against player i and, under the forfeit policy,
+// ends the match once they've had ForfeitAfter of them
+func (h *hub) timedOut(m *match, i int) bool {
+	m.timeouts[i]++
+
+	if h.config.TimeoutPolicy != TimeoutForfeit || m.timeouts[i] < max(h.config.ForfeitAfter, 1) {
+		return false
+	}
+
+	sess := m.players[i]
+	m.ending = sess.player.Name + " ran out of time"
+	m.game.Forfeit(sess.player)
+
+	return true
+}
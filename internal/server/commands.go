@@ -0,0 +1,489 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ahmaruff/go-fleet/internal/ai"
+	"github.com/ahmaruff/go-fleet/internal/game"
+	"github.com/ahmaruff/go-fleet/internal/protocol"
+	"github.com/ahmaruff/go-fleet/internal/rating"
+)
+
+// loggedArgs is command's arguments fit for the server log, passwords masked
+func loggedArgs(command protocol.Command) []string {
+	args := slices.Clone(command.Args)
+
+	switch strings.ToLower(command.Name) {
+	case "/login", "/register", "/join":
+		// the account name or room code stays, everything after it is a password
+		for i := 1; i < len(args); i++ {
+			args[i] = "***"
+		}
+	case "/create-room":
+		for i := 1; i < len(args); i++ {
+			if args[i-1] == "--password" {
+				args[i] = "***"
+			}
+		}
+	}
+
+	return args
+}
+
+func (h *hub) handleCommand(c *client, command protocol.Command) protocol.Message {
+	args := command.Args
+	name := strings.ToLower(command.Name)
+
+	switch name {
+	case "/name":
+		return h.handleName(c, args)
+	case "/register":
+		return h.handleRegister(c, args)
+	case "/login":
+		return h.handleLogin(c, args)
+	case "/leaderboard":
+		return h.handleLeaderboard(c, args)
+	case "/stats":
+		return h.handleStats(c, args)
+	}
+
+	// everything else needs a named session
+	sess := h.clients[c]
+
+	switch name {
+	case "/ready", "/cancel", "/place", "/set", "/unset", "/remove", "/clear", "/random", "/confirm", "/fire", "/radar", "/strike", "/torpedo", "/games", "/watch", "/unwatch",
+		"/create-room", "/join", "/leave-room", "/room", "/start", "/say", "/ignore", "/unignore", "/rematch", "/tournament", "/bracket":
+		if sess == nil {
+			return errorMsg(protocol.CodeNameRequired, "Please set your name first with /name")
+		}
+	}
+
+	switch name {
+	case "/ready":
+		return h.handleReady(sess, args)
+	case "/place", "/set":
+		return h.handlePlace(sess, args)
+	case "/unset":
+		return h.handleUnset(sess, args)
+	case "/remove":
+		return h.handleRemove(sess, args)
+	case "/clear":
+		return h.handleClear(sess)
+	case "/random":
+		return h.handleRandom(sess)
+	case "/confirm":
+		return h.handleConfirm(sess)
+	case "/fire":
+		return h.handleFire(sess, args)
+	case "/radar":
+		return h.handleRadar(sess, args)
+	case "/strike":
+		return h.handleStrike(sess, args)
+	case "/torpedo":
+		return h.handleTorpedo(sess, args)
+	case "/games":
+		return h.handleGames()
+	case "/watch":
+		return h.handleWatch(sess, args)
+	case "/unwatch":
+		return h.handleUnwatch(sess)
+	case "/cancel":
+		return h.handleCancel(sess)
+	case "/create-room":
+		return h.handleCreateRoom(sess, args)
+	case "/join":
+		return h.handleJoin(sess, args)
+	case "/leave-room":
+		return h.handleLeaveRoom(sess)
+	case "/room":
+		return h.handleRoomInfo(sess)
+	case "/start":
+		return h.handleStart(sess)
+	case "/say":
+		return h.handleSay(sess, args)
+	case "/ignore":
+		return h.handleIgnore(sess, args)
+	case "/unignore":
+		return h.handleUnignore(sess, args)
+	case "/rematch":
+		return h.handleRematch(sess, args)
+	case "/tournament":
+		return h.handleTournament(sess, args)
+	case "/bracket":
+		return h.handleBracket()
+	default:
+		return errorMsg(protocol.CodeUnknownCommand, "Unknown command")
+	}
+}
+
+func (h *hub) handleName(c *client, args []string) protocol.Message {
+	if len(args) < 1 {
+		return errorMsg(protocol.CodeUsage, "Usage: /name YourName")
+	}
+
+	if _, exists := h.clients[c]; exists {
+		return errorMsg(protocol.CodeAlreadyNamed, "You already have a name set. You can't change it.")
+	}
+
+	playerName := strings.Join(args, " ")
+
+	if cleanText(playerName) != playerName {
+		return errorMsg(protocol.CodeUsage, "Names can't contain control characters")
+	}
+
+	if h.nameInUse(playerName) {
+		return errorMsg(protocol.CodeNameTaken, playerName+" is already in use, pick another name")
+	}
+
+	if h.config.Store != nil {
+		if _, err := h.config.Store.Account(playerName); err == nil {
+			return errorMsg(protocol.CodeNameTaken, playerName+" is a registered player, use /login or pick another name")
+		}
+	}
+
+	h.newSession(c, playerName, false, rating.Initial)
+
+	return notice("NAME_SET", "Welcome "+playerName+"!")
+}
+
+// newSession names the player behind c, rating is only meaningful for registered players
+func (h *hub) newSession(c *client, playerName string, registered bool, rating int) *session {
+	// Create Player object, the board is handed out once a match starts
+	sess := &session{
+		token:      newToken(),
+		player:     &game.Player{Name: playerName},
+		client:     c,
+		registered: registered,
+		rating:     rating,
+	}
+
+	// Store session for this connection, the token lets a dropped connection resume it
+	h.clients[c] = sess
+	h.sessions[sess.token] = sess
+
+	h.sendSession(sess)
+	sess.effect("WELCOME")
+
+	return sess
+}
+
+func (h *hub) handleReady(sess *session, args []string) protocol.Message {
+	if h.findMatch(sess) != nil {
+		return errorMsg(protocol.CodeAlreadyInGame, "You are already in game, unable to use /ready command, use /place or /fire")
+	}
+
+	// looking for a match of your own ends watching someone else's
+	if sess.watching != nil {
+		sess.watching.removeSpectator(sess)
+	}
+
+	if sess.room != nil {
+		return errorMsg(protocol.CodeInRoom, "You're in room "+sess.room.code+", use /start or /leave-room first")
+	}
+
+	rules, args := h.pickMode(args)
+
+	if len(args) > 0 && strings.EqualFold(args[0], "ai") {
+		return h.startBotGame(sess, rules, args[1:])
+	}
+
+	if h.queue.position(sess) > 0 {
+		return h.queueNotice(sess)
+	}
+
+	h.queue.add(sess, rules, time.Now())
+	h.matchmake()
+
+	if h.queue.position(sess) == 0 {
+		return nil // paired right away
+	}
+
+	sess.effect("WAITING")
+	return h.queueNotice(sess)
+}
+
+// pickMode takes a rules variant such as "salvo" out of a command's arguments,
+// the server's default rules apply without one
+func (h *hub) pickMode(args []string) (game.Rules, []string) {
+	rules := h.config.Rules
+	var rest []string
+
+	for _, arg := range args {
+		mode, err := game.ParseMode(strings.TrimPrefix(arg, "--"))
+		if err != nil {
+			rest = append(rest, arg)
+			continue
+		}
+		rules.Mode = mode
+	}
+
+	return rules, rest
+}
+
+// startMatch opens a new series between two players with its first match
+func (h *hub) startMatch(first, second *session, rules game.Rules) *match {
+	return h.startSeriesMatch(first, second, newSeries(first, second, rules, h.config.BestOf), false)
+}
+
+// startSeriesMatch deals both players fresh boards for the next match of s,
+// first fires first, and tells them who they're up against
+func (h *hub) startSeriesMatch(first, second *session, s *series, rematch bool) *match {
+	for _, sess := range []*session{first, second} {
+		if sess.watching != nil {
+			sess.watching.removeSpectator(sess)
+		}
+
+		// a new opponent ends the rematch the last one was waiting on
+		if old := sess.series; old != nil && old != s && !slices.Contains(s.players[:], old.opponentOf(sess)) {
+			h.leaveSeries(sess)
+		}
+		sess.series = s
+	}
+
+	newGame := game.NewGameWithRules(first.player, second.player, s.rules)
+	newMatch := h.newMatch(newGame, first, second)
+	newMatch.series = s
+	s.first = first
+
+	// bots are done placing before you've picked your first ship
+	for _, sess := range newMatch.players {
+		if sess.bot != nil {
+			newGame.PlaceRandomFleetForPlayer(sess.player, h.rng)
+			newGame.ConfirmFleet(sess.player)
+		}
+	}
+
+	// Notify both players
+	switch {
+	case !rematch:
+		first.notice("GAME_START", "Match found! vs "+second.player.Name)
+		second.notice("GAME_START", "Match found! vs "+first.player.Name)
+	case s.bestOf < 2:
+		newMatch.notice("GAME_START", "Rematch! "+first.player.Name+" fires first")
+	case s.cup != nil:
+		newMatch.notice("GAME_START", fmt.Sprintf("Game %d of a best of %d, %s. %s fires first", s.game(), s.bestOf, s.score(), first.player.Name))
+	default:
+		newMatch.notice("GAME_START", fmt.Sprintf("Rematch! Game %d of a best of %d, %s. %s fires first", s.game(), s.bestOf, s.score(), first.player.Name))
+	}
+
+	first.effect("MATCH_FOUND")
+	second.effect("MATCH_FOUND")
+
+	h.startClock(newMatch)
+	h.broadcastState(newMatch)
+
+	return newMatch
+}
+
+// startBotGame matches sess against a computer opponent, /ready ai [easy|medium|hard] [classic|salvo|advanced]
+func (h *hub) startBotGame(sess *session, rules game.Rules, args []string) protocol.Message {
+	levelName := ""
+	if len(args) > 0 {
+		levelName = args[0]
+	}
+
+	level, err := ai.ParseLevel(levelName)
+	if err != nil {
+		return errorMsg(protocol.CodeUsage, "Usage: /ready ai <easy|medium|hard> [classic|salvo|advanced]")
+	}
+
+	h.dequeue(sess)
+
+	h.startMatch(sess, h.newBot(level), rules)
+
+	return nil
+}
+
+func (h *hub) handlePlace(sess *session, args []string) protocol.Message {
+	if len(args) < 3 {
+		return errorMsg(protocol.CodeUsage, "Usage: /place <ship> <coord> <H|V> (e.g. /place Carrier A1 H)")
+	}
+
+	currentMatch, errMsg := h.placingMatch(sess)
+	if errMsg != nil {
+		return errMsg
+	}
+
+	currentGame := currentMatch.game
+
+	player := sess.player
+	shipName := args[0]
+	coordinate := args[1]
+	orientation := args[2]
+	err := currentGame.PlaceShipForPlayer(player, shipName, coordinate, orientation)
+
+	if err != nil {
+		return moveError(err, "Cannot place "+shipName+" at "+coordinate)
+	}
+
+	h.fleetChanged(currentMatch, sess)
+
+	placed := player.Board.Ships[len(player.Board.Ships)-1]
+	return notice("SHIP_PLACED", fmt.Sprintf("%s placed at %s (%s) (%d/%d)%s", placed.Class.Name, strings.ToUpper(coordinate), placed.Orientation, player.Board.ShipCount, len(game.Fleet), confirmHint(player)))
+}
+
+// beginCombat starts the shooting once the game has moved on to PLAYING
+func (h *hub) beginCombat(m *match) {
+	if h.matches[m.id] != m {
+		return
+	}
+
+	// Both fleets are complete, game started!
+	m.notice("COMBAT_START", "All ships placed! Combat phase begins!")
+	for _, sess := range m.players {
+		sess.effect("BATTLE_START")
+	}
+
+	h.startClock(m)
+
+	// Send state update to everyone when combat starts
+	h.broadcastState(m)
+
+	h.scheduleBot(m)
+}
+
+func (h *hub) handleFire(sess *session, args []string) protocol.Message {
+	if len(args) < 1 {
+		return errorMsg(protocol.CodeUsage, "Usage: /fire A1")
+	}
+
+	currentMatch, errMsg := h.playingMatch(sess)
+	if errMsg != nil {
+		return errMsg
+	}
+
+	currentGame := currentMatch.game
+	isPlayer1 := currentMatch.players[0] == sess
+
+	if currentGame.Rules.Mode == game.ModeSalvo {
+		return h.fireSalvo(currentMatch, sess, args)
+	}
+
+	if len(args) > 1 {
+		return errorMsg(protocol.CodeUsage, "Classic rules fire one shot per turn: /fire A1")
+	}
+
+	player := sess.player
+	coordinate := args[0]
+
+	result, err := currentGame.FireAtOpponent(player, coordinate)
+
+	if err != nil {
+		return moveError(err, "Invalid shot at "+coordinate)
+	}
+
+	fireMsg := map[game.ShotOutcome]string{
+		game.Sunk: "HIT",
+		game.Hit:  "HIT",
+		game.Miss: "MISS",
+	}
+
+	resultMsg := fireMsg[result.Outcome]
+	fireEffect := resultMsg
+
+	if result.Outcome == game.Sunk {
+		owner := currentGame.Player1
+		if isPlayer1 {
+			owner = currentGame.Player2
+		}
+
+		sunkMsg := fmt.Sprintf("%s's %s has been sunk!", owner.Name, result.Ship.Class.Name)
+		currentMatch.notice("SHIP_SUNK", sunkMsg)
+
+		fireEffect = "VESSEL_SUNK"
+	}
+
+	currentMatch.effect(fireEffect)
+
+	return notice("SHOT_RESULT", fmt.Sprintf("%s at %s", resultMsg, strings.ToUpper(coordinate)))
+}
+
+// playingMatch finds the match sess shoots in, the game itself checks whose turn it is
+func (h *hub) playingMatch(sess *session) (*match, protocol.Message) {
+	if sess.watching != nil {
+		return nil, errorMsg(protocol.CodeSpectating, "Spectators can't fire")
+	}
+
+	currentMatch := h.findMatch(sess)
+	if currentMatch == nil {
+		return nil, errorMsg(protocol.CodeNotInGame, "You're not in a game")
+	}
+
+	return currentMatch, nil
+}
+
+// moveErrors pairs the game's typed errors with protocol codes,
+// any other refused move is an invalid_move
+var moveErrors = []struct {
+	err  error
+	code string
+}{
+	{game.ErrWrongPhase, protocol.CodeWrongPhase},
+	{game.ErrNotYourTurn, protocol.CodeNotYourTurn},
+	{game.ErrOutOfBounds, protocol.CodeOutOfBounds},
+	{game.ErrAlreadyFired, protocol.CodeAlreadyFired},
+	{game.ErrFleetComplete, protocol.CodeFleetComplete},
+	{game.ErrFleetIncomplete, protocol.CodeFleetIncomplete},
+	{game.ErrLockedIn, protocol.CodeLockedIn},
+	{game.ErrShotCount, protocol.CodeUsage},
+	{game.ErrWrongMode, protocol.CodeWrongMode},
+	{game.ErrNoCharges, protocol.CodeNoCharges},
+}
+
+// moveError reports a move the game refused, what says what was tried
+func moveError(err error, what string) protocol.Message {
+	if errors.Is(err, game.ErrNotYourTurn) {
+		return errorMsg(protocol.CodeNotYourTurn, "Not your turn! Wait for opponent to fire.")
+	}
+
+	for _, known := range moveErrors {
+		if errors.Is(err, known.err) {
+			return errorMsg(known.code, what+": "+err.Error())
+		}
+	}
+
+	return errorMsg(protocol.CodeInvalidMove, what+": "+err.Error())
+}
+
+// finishMatch announces the winner once the game has FINISHED and closes the match
+func (h *hub) finishMatch(m *match) {
+	if h.matches[m.id] != m {
+		return
+	}
+
+	winner, _ := m.game.IsGameOver()
+	sessions := m.players
+
+	winnerIndex, defeatIndex := 1, 0
+	winnerName := m.game.Player2.Name
+	if winner == 1 {
+		winnerIndex, defeatIndex = 0, 1
+		winnerName = m.game.Player1.Name
+	}
+
+	text := winnerName + " wins!"
+	if m.ending != "" {
+		text = m.ending + ", " + text
+	}
+	m.notice("GAME_OVER", text)
+
+	sessions[winnerIndex].effect("VICTORY")
+	sessions[defeatIndex].effect("DEFEAT")
+
+	h.saveReplay(m.game)
+	h.recordResult(m, winner)
+
+	// CLEANUP: Remove match from tracking
+	h.endMatch(m)
+
+	// the same two can play on with /rematch, both stay on the final board
+	// until one of them moves on
+	h.seriesResult(m, sessions[winnerIndex])
+
+	// both players are free for their next tournament match
+	h.scheduleTournament()
+}
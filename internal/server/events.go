@@ -0,0 +1,58 @@
+package server
+
+import "github.com/ahmaruff/go-fleet/internal/game"
+
+// watchGame reacts to everything the match's game records, so handlers only
+// make moves and never look at the game afterwards to see what changed.
+// Reactions wait until the move's own reply is out, see later.
+func (h *hub) watchGame(m *match) {
+	// a finished game has nothing left to time
+	m.game.OnTransition(func(from, to game.Phase) {
+		if to.Over() {
+			m.stopClock()
+		}
+	})
+
+	m.game.Events(func(e game.Event) {
+		switch e.Kind {
+		case game.EventShot, game.EventSalvo, game.EventRadar, game.EventStrike, game.EventTorpedo, game.EventSkip:
+			h.later(func() { h.nextTurn(m) })
+
+		case game.EventConfirm:
+			h.later(func() { h.fleetConfirmed(m, e.Player) })
+
+		case game.EventPhase:
+			switch e.Phase {
+			case game.PhasePlaying:
+				h.later(func() { h.beginCombat(m) })
+			case game.PhaseFinished:
+				h.later(func() { h.finishMatch(m) })
+			}
+		}
+	})
+}
+
+// later queues fn to run on the hub goroutine once the event being handled is done
+func (h *hub) later(fn func()) {
+	h.pending = append(h.pending, fn)
+}
+
+// runLater runs queued reactions in order, including any they queue themselves
+func (h *hub) runLater() {
+	for len(h.pending) > 0 {
+		fn := h.pending[0]
+		h.pending = h.pending[1:]
+		fn()
+	}
+}
+
+// nextTurn hands the turn on: a fresh clock, fresh views, and the bot if it's up
+func (h *hub) nextTurn(m *match) {
+	if h.matches[m.id] != m {
+		return // ended while the reaction was queued
+	}
+
+	h.startClock(m)
+	h.broadcastState(m)
+	h.scheduleBot(m)
+}
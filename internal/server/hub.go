@@ -0,0 +1,331 @@
+package server
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ahmaruff/go-fleet/internal/game"
+	"github.com/ahmaruff/go-fleet/internal/protocol"
+	"github.com/ahmaruff/go-fleet/internal/replay"
+)
+
+type eventKind int
+
+const (
+	eventJoin eventKind = iota
+	eventLeave
+	eventCommand
+	eventInvalid // a line that didn't decode, the client is told and may carry on
+	eventFunc    // deferred work from a timer, runs on the hub goroutine
+)
+
+type event struct {
+	kind    eventKind
+	client  *client
+	token   string // eventJoin: session the client wants to resume
+	command protocol.Command
+	err     error
+	fn      func()
+}
+
+// hub is the single goroutine that owns every session, match and the matchmaking queue
+type hub struct {
+	config Config
+	events chan event
+	quit   chan struct{}
+
+	clients     map[*client]*session // only clients that picked a name
+	sessions    map[string]*session  // by token
+	matches     map[int]*match       // by id, as listed by /games
+	nextMatchID int
+	rooms       map[string]*room // by join code
+	cup         *cup             // the tournament being run, if any
+	queue       matchQueue
+	// set while a retry is pending for pairs outside each other's rating window
+	matchmakeTimer *time.Timer
+
+	rng *rand.Rand // bots and random placement, only touched on the hub goroutine
+
+	pending []func() // reactions queued with later, run once the current event is handled
+
+	ratingsMu sync.Mutex // serialises rating updates, which run off the hub goroutine
+}
+
+func newHub(config Config) *hub {
+	return &hub{
+		config:   config,
+		events:   make(chan event),
+		quit:     make(chan struct{}),
+		clients:  make(map[*client]*session),
+		sessions: make(map[string]*session),
+		matches:  make(map[int]*match),
+		rooms:    make(map[string]*room),
+		rng:      rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())),
+	}
+}
+
+// post hands an event to the hub, returns false once the hub has stopped
+func (h *hub) post(e event) bool {
+	select {
+	case h.events <- e:
+		return true
+	case <-h.quit:
+		return false
+	}
+}
+
+// after runs fn on the hub goroutine once d has passed
+func (h *hub) after(d time.Duration, fn func()) *time.Timer {
+	return time.AfterFunc(d, func() {
+		h.post(event{kind: eventFunc, fn: fn})
+	})
+}
+
+func (h *hub) run() {
+	for {
+		select {
+		case e := <-h.events:
+			h.handleEvent(e)
+			h.runLater()
+		case <-h.quit:
+			return
+		}
+	}
+}
+
+func (h *hub) handleEvent(e event) {
+	switch e.kind {
+	case eventJoin:
+		if e.token != "" {
+			h.resumeSession(e.client, e.token)
+		}
+
+	case eventLeave:
+		h.handleLeave(e.client)
+
+	case eventCommand:
+		if e.client.closed {
+			return
+		}
+
+		h.logf("[SERVER] Received: %s %s\n", e.command.Name, strings.Join(loggedArgs(e.command), " "))
+
+		response := h.handleCommand(e.client, e.command) // Pass client to track who sent it
+		e.client.send(response)
+
+	case eventInvalid:
+		e.client.send(errorMsg(protocol.CodeBadRequest, e.err.Error()))
+
+	case eventFunc:
+		e.fn()
+	}
+}
+
+func (h *hub) handleLeave(c *client) {
+	defer c.close()
+
+	sess := h.clients[c]
+	if sess == nil {
+		return
+	}
+
+	delete(h.clients, c) // Clean up when client disconnects
+	sess.client = nil
+
+	h.dequeue(sess)
+
+	if sess.watching != nil {
+		sess.watching.removeSpectator(sess)
+	}
+
+	currentMatch := h.findMatch(sess)
+	if (currentMatch == nil && !h.inTournament(sess)) || h.config.ReconnectGrace <= 0 {
+		h.endSession(sess)
+		return
+	}
+
+	// hold the match or tournament place, the player has ReconnectGrace to come back with their token
+	if currentMatch != nil {
+		opponent := currentMatch.opponentOf(sess)
+		opponent.notice("OPPONENT_RECONNECTING", fmt.Sprintf("%s lost connection, holding the match for %s", sess.player.Name, h.config.ReconnectGrace))
+	} else {
+		h.tournamentNotice(h.cup, fmt.Sprintf("%s lost connection, holding their place in %s for %s", sess.player.Name, h.cup.bracket.Name, h.config.ReconnectGrace))
+	}
+
+	var timer *time.Timer
+	timer = h.after(h.config.ReconnectGrace, func() {
+		if sess.client != nil || sess.grace != timer {
+			return // resumed in the meantime
+		}
+
+		h.endSession(sess)
+	})
+	sess.grace = timer
+}
+
+// endSession forgets a session for good, forfeiting any match it was in
+func (h *hub) endSession(sess *session) {
+	delete(h.sessions, sess.token)
+
+	// once any match of theirs is settled, a forfeit only finishes later
+	defer h.later(func() { h.playerGone(sess) })
+
+	if sess.room != nil {
+		h.leaveRoom(sess)
+	}
+	h.leaveSeries(sess)
+
+	currentMatch := h.findMatch(sess)
+	if currentMatch == nil {
+		return
+	}
+
+	opponent := currentMatch.opponentOf(sess)
+
+	// walking out of a battle counts as a loss, finished, rated and saved like any other
+	if currentMatch.game.Phase == game.PhasePlaying {
+		currentMatch.ending = sess.player.Name + " left the match"
+		currentMatch.game.Forfeit(sess.player)
+
+		// once the result is out
+		h.later(func() {
+			opponent.notice("OPPONENT_DISCONNECTED", "Your opponent left the match")
+		})
+		return
+	}
+
+	currentMatch.game.Abort()
+
+	// Remove match from tracking
+	currentMatch.spectatorNotice("GAME_OVER", sess.player.Name+" left the match")
+	h.endMatch(currentMatch)
+
+	// Notify the remaining player (if still connected)
+	opponent.notice("OPPONENT_DISCONNECTED", "Your opponent left the match")
+}
+
+// resumeSession re-attaches c to the session behind token and resyncs its game
+func (h *hub) resumeSession(c *client, token string) {
+	sess := h.sessions[token]
+	if sess == nil {
+		c.send(errorMsg(protocol.CodeSessionExpired, "Session expired, please set your name again"))
+		return
+	}
+
+	// the old connection may not have noticed it's dead yet, this one wins
+	if old := sess.client; old != nil {
+		delete(h.clients, old)
+		old.close()
+		old.conn.Close()
+	}
+
+	if sess.grace != nil {
+		sess.grace.Stop()
+		sess.grace = nil
+	}
+
+	sess.client = c
+	h.clients[c] = sess
+
+	h.sendSession(sess)
+	sess.notice("RESUMED", "Welcome back "+sess.player.Name+"!")
+
+	// a tournament match may have been waiting for them
+	h.scheduleTournament()
+
+	currentMatch := h.findMatch(sess)
+	if currentMatch == nil {
+		return
+	}
+
+	sess.state(h.viewFor(currentMatch, sess))
+	currentMatch.opponentOf(sess).notice("OPPONENT_RECONNECTED", sess.player.Name+" is back!")
+}
+
+func (h *hub) sendSession(sess *session) {
+	if sess.client == nil || sess.client.version < 3 {
+		return
+	}
+
+	sess.send(protocol.Session{
+		Token:        sess.token,
+		Name:         sess.player.Name,
+		GraceSeconds: int(h.config.ReconnectGrace / time.Second),
+		Registered:   sess.registered,
+	})
+}
+
+func (h *hub) newMatch(g *game.Game, p1, p2 *session) *match {
+	h.nextMatchID++
+	m := &match{id: h.nextMatchID, game: g, players: [2]*session{p1, p2}}
+	h.matches[m.id] = m
+	h.watchGame(m)
+	return m
+}
+
+// endMatch stops tracking a match and sends its spectators back to the lobby
+func (h *hub) endMatch(m *match) {
+	delete(h.matches, m.id)
+	m.stopClock()
+
+	for _, spectator := range m.spectators {
+		spectator.watching = nil
+		spectator.notice("WATCH_END", "The match is over")
+	}
+	m.spectators = nil
+}
+
+// findMatch finds the match sess is playing in, spectators aren't players
+func (h *hub) findMatch(sess *session) *match {
+	for _, m := range h.matches {
+		if m.players[0] == sess || m.players[1] == sess {
+			return m
+		}
+	}
+	return nil
+}
+
+// viewFor builds the fog-of-war filtered snapshot for whichever player or spectator sess is
+func (h *hub) viewFor(m *match, sess *session) game.View {
+	if sess.watching == m {
+		view := m.game.SpectatorView(h.config.SpectatorReveal)
+		view.Series = m.series.scoreFor(m.players[0]) // Own is Player1's board
+		return view
+	}
+
+	view := m.game.ViewFor(sess.player)
+	view.Series = m.series.scoreFor(sess)
+	return view
+}
+
+// broadcastState sends every player and spectator their own view of the match
+func (h *hub) broadcastState(m *match) {
+	for _, sess := range m.players {
+		sess.state(h.viewFor(m, sess))
+	}
+	for _, spectator := range m.spectators {
+		spectator.state(h.viewFor(m, spectator))
+	}
+}
+
+// saveReplay writes the finished match's history off the hub goroutine
+func (h *hub) saveReplay(g *game.Game) {
+	if h.config.ReplayDir == "" {
+		return
+	}
+
+	name := replay.FileName(g, time.Now())
+	events := append([]game.Event(nil), g.History...)
+
+	go func() {
+		path, err := replay.Save(h.config.ReplayDir, name, events)
+		if err != nil {
+			h.logf("[SERVER] Failed to save replay: %v\n", err)
+			return
+		}
+		h.logf("[SERVER] Replay saved to %s\n", path)
+	}()
+}
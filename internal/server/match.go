@@ -0,0 +1,60 @@
+package server
+
+import (
+	"time"
+
+	"github.com/ahmaruff/go-fleet/internal/game"
+)
+
+// match is a running game with the sessions playing and watching it
+type match struct {
+	id         int
+	game       *game.Game
+	players    [2]*session
+	spectators []*session
+
+	clock    []*time.Timer // the running deadline and its warnings
+	timeouts [2]int        // deadlines each player has let run out
+
+	ending string // how the game ended if not by a sunk fleet, for GAME_OVER
+
+	series *series // the series this match is a game of
+}
+
+func (m *match) opponentOf(sess *session) *session {
+	if m.players[0] == sess {
+		return m.players[1]
+	}
+	return m.players[0]
+}
+
+// notice goes to both players and everyone watching
+func (m *match) notice(tag, text string) {
+	m.players[0].notice(tag, text)
+	m.players[1].notice(tag, text)
+	m.spectatorNotice(tag, text)
+}
+
+func (m *match) spectatorNotice(tag, text string) {
+	for _, spectator := range m.spectators {
+		spectator.notice(tag, text)
+	}
+}
+
+func (m *match) effect(name string) {
+	m.players[0].effect(name)
+	m.players[1].effect(name)
+	for _, spectator := range m.spectators {
+		spectator.effect(name)
+	}
+}
+
+func (m *match) removeSpectator(sess *session) {
+	for i, spectator := range m.spectators {
+		if spectator == sess {
+			m.spectators = append(m.spectators[:i], m.spectators[i+1:]...)
+			break
+		}
+	}
+	sess.watching = nil
+}
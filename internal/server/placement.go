@@ -0,0 +1,155 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ahmaruff/go-fleet/internal/game"
+	"github.com/ahmaruff/go-fleet/internal/protocol"
+)
+
+// placingMatch finds the match sess places a fleet in, the game itself checks the phase
+func (h *hub) placingMatch(sess *session) (*match, protocol.Message) {
+	if sess.watching != nil {
+		return nil, errorMsg(protocol.CodeSpectating, "Spectators can't place ships")
+	}
+
+	currentMatch := h.findMatch(sess)
+	if currentMatch == nil {
+		return nil, errorMsg(protocol.CodeNotInGame, "You're not in a game. Use /ready first")
+	}
+
+	return currentMatch, nil
+}
+
+// fleetChanged shows sess their board after a placement or removal. The
+// opponent can't see it, spectators may.
+func (h *hub) fleetChanged(m *match, sess *session) {
+	if sess.player.Board.IsFleetComplete() {
+		sess.effect("ALL_SHIPS_READY")
+	}
+
+	sess.state(h.viewFor(m, sess))
+	for _, spectator := range m.spectators {
+		spectator.state(h.viewFor(m, spectator))
+	}
+}
+
+// confirmHint reminds a player with a complete fleet that it still needs locking in
+func confirmHint(p *game.Player) string {
+	if !p.Board.IsFleetComplete() {
+		return ""
+	}
+	return " - fleet complete, /confirm to lock it in"
+}
+
+// handleUnset takes back the ship covering a cell, /unset A1
+func (h *hub) handleUnset(sess *session, args []string) protocol.Message {
+	if len(args) != 1 {
+		return errorMsg(protocol.CodeUsage, "Usage: /unset <coord> (e.g. /unset A1)")
+	}
+
+	m, errMsg := h.placingMatch(sess)
+	if errMsg != nil {
+		return errMsg
+	}
+
+	ship, err := m.game.RemoveShipAtForPlayer(sess.player, args[0])
+	if err != nil {
+		return moveError(err, "Cannot unset "+strings.ToUpper(args[0]))
+	}
+
+	return h.shipRemoved(m, sess, ship)
+}
+
+// handleRemove takes back a ship by name, /remove Carrier
+func (h *hub) handleRemove(sess *session, args []string) protocol.Message {
+	if len(args) != 1 {
+		return errorMsg(protocol.CodeUsage, "Usage: /remove <ship> (e.g. /remove Carrier)")
+	}
+
+	m, errMsg := h.placingMatch(sess)
+	if errMsg != nil {
+		return errMsg
+	}
+
+	ship, err := m.game.RemoveShipForPlayer(sess.player, args[0])
+	if err != nil {
+		return moveError(err, "Cannot remove "+args[0])
+	}
+
+	return h.shipRemoved(m, sess, ship)
+}
+
+func (h *hub) shipRemoved(m *match, sess *session, ship game.Ship) protocol.Message {
+	h.fleetChanged(m, sess)
+
+	board := sess.player.Board
+	return notice("SHIP_REMOVED", fmt.Sprintf("%s removed from %s (%d/%d)", ship.Class.Name, game.CellName(ship.Row, ship.Col), board.ShipCount, len(game.Fleet)))
+}
+
+// handleClear takes the whole fleet back off the board
+func (h *hub) handleClear(sess *session) protocol.Message {
+	m, errMsg := h.placingMatch(sess)
+	if errMsg != nil {
+		return errMsg
+	}
+
+	if err := m.game.ClearFleetForPlayer(sess.player); err != nil {
+		return moveError(err, "Cannot clear your fleet")
+	}
+
+	h.fleetChanged(m, sess)
+
+	return notice("FLEET_CLEARED", "All ships taken back, place them again with /place or /random")
+}
+
+// handleRandom places whatever is left of the fleet at random
+func (h *hub) handleRandom(sess *session) protocol.Message {
+	m, errMsg := h.placingMatch(sess)
+	if errMsg != nil {
+		return errMsg
+	}
+
+	if err := m.game.PlaceRandomFleetForPlayer(sess.player, h.rng); err != nil {
+		return moveError(err, "Cannot place your fleet at random")
+	}
+
+	h.fleetChanged(m, sess)
+
+	return notice("SHIP_PLACED", "Fleet placed at random"+confirmHint(sess.player))
+}
+
+// handleConfirm locks a complete fleet in, combat starts once both players have
+func (h *hub) handleConfirm(sess *session) protocol.Message {
+	m, errMsg := h.placingMatch(sess)
+	if errMsg != nil {
+		return errMsg
+	}
+
+	if err := m.game.ConfirmFleet(sess.player); err != nil {
+		return moveError(err, "Cannot confirm your fleet")
+	}
+
+	sess.state(h.viewFor(m, sess))
+
+	opponent := m.opponentOf(sess)
+	if !m.game.Confirmed(opponent.player) {
+		return notice("FLEET_CONFIRMED", "Fleet locked in! Waiting for "+opponent.player.Name+"...")
+	}
+
+	return notice("FLEET_CONFIRMED", "Fleet locked in!")
+}
+
+// fleetConfirmed tells everyone but the player that they've locked in
+func (h *hub) fleetConfirmed(m *match, player int) {
+	if h.matches[m.id] != m {
+		return
+	}
+
+	sess := m.players[player-1]
+	text := sess.player.Name + " has locked in their fleet"
+
+	m.opponentOf(sess).notice("OPPONENT_READY", text)
+	m.spectatorNotice("OPPONENT_READY", text)
+}
@@ -0,0 +1,159 @@
+package server
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ahmaruff/go-fleet/internal/game"
+	"github.com/ahmaruff/go-fleet/internal/protocol"
+)
+
+// how often a rating-windowed queue looks again for pairs it couldn't make
+const matchmakeInterval = time.Second
+
+type queueEntry struct {
+	sess  *session
+	rules game.Rules // players only meet others who asked for the same mode
+	since time.Time
+}
+
+// matchQueue holds the players looking for a random opponent, oldest first
+type matchQueue struct {
+	entries []queueEntry
+}
+
+// position is 1 for the front of the queue, 0 when sess isn't queued
+func (q *matchQueue) position(sess *session) int {
+	for i, entry := range q.entries {
+		if entry.sess == sess {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+func (q *matchQueue) add(sess *session, rules game.Rules, now time.Time) int {
+	if pos := q.position(sess); pos > 0 {
+		return pos
+	}
+
+	q.entries = append(q.entries, queueEntry{sess: sess, rules: rules, since: now})
+	return len(q.entries)
+}
+
+func (q *matchQueue) remove(sess *session) bool {
+	pos := q.position(sess)
+	if pos == 0 {
+		return false
+	}
+
+	q.entries = append(q.entries[:pos-1], q.entries[pos:]...)
+	return true
+}
+
+// modePair reports whether two entries asked for the same mode, the only
+// pairs a widening rating window could still make
+func (q *matchQueue) modePair() bool {
+	seen := make(map[game.Mode]bool)
+	for _, entry := range q.entries {
+		if seen[entry.rules.Mode] {
+			return true
+		}
+		seen[entry.rules.Mode] = true
+	}
+	return false
+}
+
+// dequeue takes sess out of the queue and tells everyone behind them where they stand now
+func (h *hub) dequeue(sess *session) bool {
+	pos := h.queue.position(sess)
+	if !h.queue.remove(sess) {
+		return false
+	}
+
+	h.announceQueue(pos - 1)
+	return true
+}
+
+// announceQueue sends the new position to every entry from index from on
+func (h *hub) announceQueue(from int) {
+	for _, entry := range h.queue.entries[min(from, len(h.queue.entries)):] {
+		entry.sess.send(h.queueNotice(entry.sess))
+	}
+}
+
+// window is how far apart in rating an entry accepts an opponent, it widens the longer they wait
+func (h *hub) window(entry queueEntry, now time.Time) int {
+	waited := int(now.Sub(entry.since) / time.Second)
+	return h.config.MatchWindow + waited*h.config.MatchWindowGrowth
+}
+
+// compatible pairs anyone when there's no rating window, otherwise whoever has
+// waited longest decides how wide to look
+func (h *hub) compatible(a, b queueEntry, now time.Time) bool {
+	if a.rules.Mode != b.rules.Mode {
+		return false
+	}
+
+	if h.config.MatchWindow <= 0 {
+		return true
+	}
+
+	diff := a.sess.rating - b.sess.rating
+	if diff < 0 {
+		diff = -diff
+	}
+
+	return diff <= max(h.window(a, now), h.window(b, now))
+}
+
+// matchmake starts a match for every pair it can make, oldest players first
+func (h *hub) matchmake() {
+	now := time.Now()
+	// the first slot a pairing emptied, everyone from there on moved up
+	moved := len(h.queue.entries)
+
+	for i := 0; i < len(h.queue.entries); i++ {
+		a := h.queue.entries[i]
+
+		for j := i + 1; j < len(h.queue.entries); j++ {
+			b := h.queue.entries[j]
+			if !h.compatible(a, b, now) {
+				continue
+			}
+
+			h.queue.remove(a.sess)
+			h.queue.remove(b.sess)
+			h.startMatch(a.sess, b.sess, a.rules)
+			moved = min(moved, i)
+
+			i-- // a's slot now holds the next player in line
+			break
+		}
+	}
+
+	h.announceQueue(moved)
+
+	// windows keep widening, look again in a bit while two players of a mode are still apart
+	if h.config.MatchWindow > 0 && h.config.MatchWindowGrowth > 0 && h.queue.modePair() && h.matchmakeTimer == nil {
+		h.matchmakeTimer = h.after(matchmakeInterval, func() {
+			h.matchmakeTimer = nil
+			h.matchmake()
+		})
+	}
+}
+
+func (h *hub) queueNotice(sess *session) protocol.Message {
+	pos := h.queue.position(sess)
+	entry := h.queue.entries[pos-1]
+	return notice("WAITING", fmt.Sprintf("Looking for opponent (%s)... (position %d of %d in queue)", entry.rules.Mode, pos, len(h.queue.entries)))
+}
+
+// handleCancel leaves the matchmaking queue, /cancel
+func (h *hub) handleCancel(sess *session) protocol.Message {
+	if !h.dequeue(sess) {
+		return errorMsg(protocol.CodeNotQueued, "You're not in the matchmaking queue")
+	}
+
+	return notice("QUEUE_LEFT", "Left the matchmaking queue")
+}
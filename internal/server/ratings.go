@@ -0,0 +1,173 @@
+package server
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ahmaruff/go-fleet/internal/protocol"
+	"github.com/ahmaruff/go-fleet/internal/rating"
+	"github.com/ahmaruff/go-fleet/internal/store"
+)
+
+const (
+	defaultLeaderboard = 10
+	maxLeaderboard     = 50
+)
+
+// rated matches are the ones between two logged-in players, guests and bots don't count
+func (h *hub) rated(m *match) bool {
+	return h.config.Store != nil && m.players[0].registered && m.players[1].registered
+}
+
+// recordResult moves both ratings and adds the match to both players' stats.
+// The store is updated off the hub goroutine, players hear back once it's saved.
+func (h *hub) recordResult(m *match, winner int) {
+	if !h.rated(m) {
+		return
+	}
+
+	w, l := winner-1, 2-winner
+	players := m.players
+
+	var shots, hits [2]int
+	for i, sess := range players {
+		shots[i], hits[i] = m.game.ShotStats(sess.player)
+	}
+
+	go func() {
+		// one result at a time, each is a read-modify-write of two accounts
+		h.ratingsMu.Lock()
+		defer h.ratingsMu.Unlock()
+
+		var accounts [2]store.Account
+		for i, sess := range players {
+			account, err := h.config.Store.Account(sess.player.Name)
+			if err != nil {
+				h.logf("[SERVER] Failed to load %s for rating: %v\n", sess.player.Name, err)
+				return
+			}
+			accounts[i] = account
+		}
+
+		before := [2]int{accounts[0].Stats.Rating, accounts[1].Stats.Rating}
+		accounts[w].Stats.Rating, accounts[l].Stats.Rating = rating.Update(before[w], before[l])
+
+		accounts[w].Stats.Wins++
+		accounts[w].Stats.WinShots += shots[w]
+		accounts[l].Stats.Losses++
+
+		for i := range accounts {
+			accounts[i].Stats.Shots += shots[i]
+			accounts[i].Stats.Hits += hits[i]
+		}
+
+		// both or neither, one player's rating never moves on its own
+		if err := h.config.Store.UpdateAccounts(accounts[:]...); err != nil {
+			h.logf("[SERVER] Failed to save ratings for %s and %s: %v\n", accounts[0].Name, accounts[1].Name, err)
+			return
+		}
+
+		h.post(event{kind: eventFunc, fn: func() {
+			for i, sess := range players {
+				after := accounts[i].Stats.Rating
+				sess.rating = after
+				sess.notice("RATING", fmt.Sprintf("Your rating: %d (%+d)", after, after-before[i]))
+			}
+		}})
+	}()
+}
+
+// handleLeaderboard lists the best rated players, /leaderboard [n]
+func (h *hub) handleLeaderboard(c *client, args []string) protocol.Message {
+	if h.config.Store == nil {
+		return errorMsg(protocol.CodeAccountsDisabled, "Accounts are disabled on this server, there's no leaderboard")
+	}
+
+	n := defaultLeaderboard
+	if len(args) > 0 {
+		var err error
+		n, err = strconv.Atoi(args[0])
+		if err != nil || n < 1 {
+			return errorMsg(protocol.CodeUsage, "Usage: /leaderboard [n]")
+		}
+		n = min(n, maxLeaderboard)
+	}
+
+	h.lookup(c, func() protocol.Message {
+		accounts, err := h.config.Store.Accounts()
+		if err != nil {
+			return errorMsg(protocol.CodeBadRequest, "Could not load the leaderboard, try again later")
+		}
+
+		accounts = slices.DeleteFunc(accounts, func(a store.Account) bool {
+			return a.Stats.Played() == 0
+		})
+		if len(accounts) == 0 {
+			return notice("LEADERBOARD", "No rated matches played yet")
+		}
+
+		slices.SortFunc(accounts, func(a, b store.Account) int {
+			if a.Stats.Rating != b.Stats.Rating {
+				return b.Stats.Rating - a.Stats.Rating
+			}
+			return strings.Compare(a.Name, b.Name)
+		})
+
+		var board strings.Builder
+		board.WriteString("Leaderboard:")
+		for i, account := range accounts[:min(n, len(accounts))] {
+			board.WriteString(fmt.Sprintf("\n  %2d. %-20s %5d  %d-%d", i+1, account.Name, account.Stats.Rating, account.Stats.Wins, account.Stats.Losses))
+		}
+
+		return notice("LEADERBOARD", board.String())
+	})
+
+	return nil
+}
+
+// handleStats shows one player's record, /stats <name>, your own without a name
+func (h *hub) handleStats(c *client, args []string) protocol.Message {
+	if h.config.Store == nil {
+		return errorMsg(protocol.CodeAccountsDisabled, "Accounts are disabled on this server, there are no stats")
+	}
+
+	name := ""
+	if len(args) > 0 {
+		name = args[0]
+	} else if sess := h.clients[c]; sess != nil && sess.registered {
+		name = sess.player.Name
+	}
+
+	if name == "" {
+		return errorMsg(protocol.CodeUsage, "Usage: /stats <name>")
+	}
+
+	h.lookup(c, func() protocol.Message {
+		account, err := h.config.Store.Account(name)
+		if err != nil {
+			return errorMsg(protocol.CodeNoSuchPlayer, "No registered player named "+name)
+		}
+
+		s := account.Stats
+		text := fmt.Sprintf("%s: rating %d, %d wins, %d losses, accuracy %.1f%%", account.Name, s.Rating, s.Wins, s.Losses, s.Accuracy()*100)
+		if s.Wins > 0 {
+			text += fmt.Sprintf(", %.1f shots to win on average", s.AvgShotsToWin())
+		}
+
+		return notice("STATS", text)
+	})
+
+	return nil
+}
+
+// lookup runs a store query off the hub goroutine and sends its answer to c
+func (h *hub) lookup(c *client, query func() protocol.Message) {
+	go func() {
+		response := query()
+		h.post(event{kind: eventFunc, fn: func() {
+			c.send(response)
+		}})
+	}()
+}
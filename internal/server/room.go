@@ -0,0 +1,245 @@
+package server
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"strings"
+
+	"github.com/ahmaruff/go-fleet/internal/game"
+	"github.com/ahmaruff/go-fleet/internal/protocol"
+)
+
+// join codes skip look-alike characters such as 0/O and 1/I
+const (
+	roomCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	roomCodeLength   = 5
+)
+
+// room is a private lobby: the host and first guest are seated, everyone
+// after them watches the matches the host starts
+type room struct {
+	code     string
+	name     string
+	password string // empty for rooms anyone with the code can join
+	rules    game.Rules
+	host     *session
+	members  []*session // in join order, the first two are seated
+}
+
+func (r *room) seated() []*session {
+	return r.members[:min(2, len(r.members))]
+}
+
+func (r *room) notice(tag, text string) {
+	for _, member := range r.members {
+		member.notice(tag, text)
+	}
+}
+
+// describe lists the room for /room and for whoever just joined
+func (r *room) describe() string {
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("Room %s (%s), %s rules", r.name, r.code, r.rules.Mode))
+	if r.password != "" {
+		text.WriteString(", password protected")
+	}
+
+	for i, member := range r.members {
+		role := "watching"
+		if i < 2 {
+			role = "seated"
+		}
+		if member == r.host {
+			role += ", host"
+		}
+		text.WriteString(fmt.Sprintf("\n  %s (%s)", member.player.Name, role))
+	}
+
+	return text.String()
+}
+
+func (h *hub) newRoomCode() string {
+	for {
+		code := make([]byte, roomCodeLength)
+		for i := range code {
+			code[i] = roomCodeAlphabet[h.rng.IntN(len(roomCodeAlphabet))]
+		}
+
+		if h.rooms[string(code)] == nil {
+			return string(code)
+		}
+	}
+}
+
+// handleCreateRoom opens a room with sess as host, /create-room [name] [--password <password>] [--salvo|--advanced]
+func (h *hub) handleCreateRoom(sess *session, args []string) protocol.Message {
+	if sess.room != nil {
+		return errorMsg(protocol.CodeInRoom, "You're already in room "+sess.room.code+", use /leave-room first")
+	}
+
+	if h.findMatch(sess) != nil {
+		return errorMsg(protocol.CodeAlreadyInGame, "You can't open a room during a match")
+	}
+
+	var nameParts []string
+	password := ""
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--password" {
+			if i+1 >= len(args) {
+				return errorMsg(protocol.CodeUsage, "Usage: /create-room [name] [--password <password>] [--salvo|--advanced]")
+			}
+			password = args[i+1]
+			i++
+			continue
+		}
+		nameParts = append(nameParts, args[i])
+	}
+
+	// --salvo, --advanced and --classic pick the room's rules, the password is already out of the way
+	rules, nameParts := h.pickMode(nameParts)
+
+	r := &room{
+		code:     h.newRoomCode(),
+		name:     cleanText(strings.Join(nameParts, " ")),
+		password: password,
+		rules:    rules,
+		host:     sess,
+	}
+	if r.name == "" {
+		r.name = sess.player.Name + "'s room"
+	}
+
+	h.rooms[r.code] = r
+	h.enterRoom(sess, r)
+
+	return notice("ROOM_CREATED", fmt.Sprintf("Room %s is open, share the join code %s (/join %s)", r.name, r.code, r.code))
+}
+
+// handleJoin takes a seat in a room, or a spot watching if both are taken, /join <code> [password]
+func (h *hub) handleJoin(sess *session, args []string) protocol.Message {
+	if len(args) < 1 {
+		return errorMsg(protocol.CodeUsage, "Usage: /join <code> [password]")
+	}
+
+	if sess.room != nil {
+		return errorMsg(protocol.CodeInRoom, "You're already in room "+sess.room.code+", use /leave-room first")
+	}
+
+	if h.findMatch(sess) != nil {
+		return errorMsg(protocol.CodeAlreadyInGame, "You can't join a room during a match")
+	}
+
+	r := h.rooms[strings.ToUpper(args[0])]
+	if r == nil {
+		return errorMsg(protocol.CodeNoSuchRoom, "No room with code "+strings.ToUpper(args[0]))
+	}
+
+	given := ""
+	if len(args) > 1 {
+		given = args[1]
+	}
+	if r.password != "" && subtle.ConstantTimeCompare([]byte(given), []byte(r.password)) != 1 {
+		return errorMsg(protocol.CodeWrongPassword, "Wrong password for room "+r.code)
+	}
+
+	r.notice("ROOM_JOINED", sess.player.Name+" joined the room")
+	h.enterRoom(sess, r)
+
+	return notice("ROOM", r.describe())
+}
+
+func (h *hub) enterRoom(sess *session, r *room) {
+	// a room replaces the public queue and whatever you were watching
+	h.dequeue(sess)
+	if sess.watching != nil {
+		sess.watching.removeSpectator(sess)
+	}
+
+	h.leaveSeries(sess)
+
+	r.members = append(r.members, sess)
+	sess.room = r
+}
+
+func (h *hub) handleLeaveRoom(sess *session) protocol.Message {
+	if sess.room == nil {
+		return errorMsg(protocol.CodeNotInRoom, "You're not in a room")
+	}
+
+	code := sess.room.code
+	h.leaveRoom(sess)
+
+	return notice("ROOM_LEFT", "You left room "+code)
+}
+
+// leaveRoom hands the host role to the next member, an empty room closes
+func (h *hub) leaveRoom(sess *session) {
+	r := sess.room
+	sess.room = nil
+
+	for i, member := range r.members {
+		if member == sess {
+			r.members = append(r.members[:i], r.members[i+1:]...)
+			break
+		}
+	}
+
+	if len(r.members) == 0 {
+		delete(h.rooms, r.code)
+		return
+	}
+
+	r.notice("ROOM_LEFT", sess.player.Name+" left the room")
+
+	if r.host == sess {
+		r.host = r.members[0]
+		r.notice("ROOM_HOST", r.host.player.Name+" is now the host")
+	}
+}
+
+func (h *hub) handleRoomInfo(sess *session) protocol.Message {
+	if sess.room == nil {
+		return errorMsg(protocol.CodeNotInRoom, "You're not in a room, use /create-room or /join <code>")
+	}
+
+	return notice("ROOM", sess.room.describe())
+}
+
+// handleStart lets the host start a match between the two seated members
+func (h *hub) handleStart(sess *session) protocol.Message {
+	r := sess.room
+	if r == nil {
+		return errorMsg(protocol.CodeNotInRoom, "You're not in a room, use /ready for a random opponent")
+	}
+
+	if r.host != sess {
+		return errorMsg(protocol.CodeNotHost, "Only the host ("+r.host.player.Name+") can start the match")
+	}
+
+	seated := r.seated()
+	if len(seated) < 2 {
+		return errorMsg(protocol.CodeWrongPhase, "Waiting for a second player, share the join code "+r.code)
+	}
+
+	for _, member := range seated {
+		if h.findMatch(member) != nil {
+			return errorMsg(protocol.CodeAlreadyInGame, member.player.Name+" is still playing a match")
+		}
+	}
+
+	m := h.startMatch(seated[0], seated[1], r.rules)
+
+	// the rest of the room watches
+	for _, member := range r.members[2:] {
+		if member.watching != nil {
+			member.watching.removeSpectator(member)
+		}
+
+		m.spectators = append(m.spectators, member)
+		member.watching = m
+		member.notice("WATCHING", fmt.Sprintf("Watching #%d %s vs %s", m.id, seated[0].player.Name, seated[1].player.Name))
+		member.state(h.viewFor(m, member))
+	}
+
+	return nil
+}
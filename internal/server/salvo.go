@@ -0,0 +1,79 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ahmaruff/go-fleet/internal/ai"
+	"github.com/ahmaruff/go-fleet/internal/game"
+	"github.com/ahmaruff/go-fleet/internal/protocol"
+)
+
+// fireSalvo fires a whole salvo turn, /fire A1 B2 C3 with one cell per ship afloat
+func (h *hub) fireSalvo(m *match, sess *session, cells []string) protocol.Message {
+	g := m.game
+
+	results, err := g.FireSalvo(sess.player, cells)
+	if errors.Is(err, game.ErrShotCount) {
+		allowed := g.ShotsAllowed(sess.player)
+		return errorMsg(protocol.CodeUsage, fmt.Sprintf("Salvo: fire exactly %d shots this turn, e.g. /fire %s", allowed, strings.Join(exampleCells(allowed), " ")))
+	}
+	if err != nil {
+		return moveError(err, "Invalid salvo")
+	}
+
+	fireEffect, summary := m.reportShots(sess, results)
+
+	m.effect(fireEffect)
+
+	return notice("SHOT_RESULT", "Salvo: "+strings.Join(summary, ", "))
+}
+
+// reportShots announces the ships a batch of shots sank and sums the batch up,
+// the loudest result sets the effect
+func (m *match) reportShots(sess *session, results []game.ShotResult) (string, []string) {
+	owner := m.opponentOf(sess).player
+
+	fireEffect := "MISS"
+	var summary []string
+	for _, result := range results {
+		cell := game.CellName(result.Row, result.Col)
+
+		switch result.Outcome {
+		case game.Sunk:
+			m.notice("SHIP_SUNK", fmt.Sprintf("%s's %s has been sunk!", owner.Name, result.Ship.Class.Name))
+			fireEffect = "VESSEL_SUNK"
+			summary = append(summary, "HIT at "+cell)
+		case game.Hit:
+			if fireEffect == "MISS" {
+				fireEffect = "HIT"
+			}
+			summary = append(summary, "HIT at "+cell)
+		default:
+			summary = append(summary, "MISS at "+cell)
+		}
+	}
+
+	return fireEffect, summary
+}
+
+// pickShots chooses a full turn of cells for sess, one shot in classic or a whole salvo
+func (h *hub) pickShots(m *match, sess *session, strategy ai.Strategy) []string {
+	opponent := m.game.ViewFor(sess.player).Opponent
+
+	var cells []string
+	for _, cell := range ai.Volley(strategy, opponent, m.game.ShotsAllowed(sess.player)) {
+		cells = append(cells, game.CellName(cell[0], cell[1]))
+	}
+
+	return cells
+}
+
+func exampleCells(n int) []string {
+	cells := make([]string, n)
+	for i := range cells {
+		cells[i] = game.CellName(i, i)
+	}
+	return cells
+}
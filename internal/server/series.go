@@ -0,0 +1,223 @@
+package server
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ahmaruff/go-fleet/internal/game"
+	"github.com/ahmaruff/go-fleet/internal/protocol"
+	"github.com/ahmaruff/go-fleet/internal/tournament"
+)
+
+// series is a run of matches between the same two players, won by whoever
+// takes the majority of bestOf games. /rematch plays the next one.
+type series struct {
+	players [2]*session // seated as in the series' first match
+	wins    [2]int
+	bestOf  int
+	rules   game.Rules
+	first   *session // fired first in the latest match, the rematch swaps it
+
+	// an open /rematch offer, offerBestOf is the length of the new series it
+	// would start once this one is decided
+	offer       *session
+	offerBestOf int
+
+	// set for a tournament match, which the series decides
+	cup  *cup
+	bout *tournament.Match
+}
+
+func newSeries(first, second *session, rules game.Rules, bestOf int) *series {
+	return &series{
+		players: [2]*session{first, second},
+		bestOf:  max(bestOf, 1),
+		rules:   rules,
+	}
+}
+
+func (s *series) index(sess *session) int {
+	if s.players[0] == sess {
+		return 0
+	}
+	return 1
+}
+
+func (s *series) opponentOf(sess *session) *session {
+	return s.players[1-s.index(sess)]
+}
+
+// game is the number of the match being played, or the next one between matches
+func (s *series) game() int {
+	return s.wins[0] + s.wins[1] + 1
+}
+
+// decided reports whether one player has taken a majority of the games
+func (s *series) decided() bool {
+	return max(s.wins[0], s.wins[1]) > s.bestOf/2
+}
+
+// scoreFor is the series from sess's side, nil outside a series of more than one game
+func (s *series) scoreFor(sess *session) *game.SeriesScore {
+	if s == nil || s.bestOf < 2 {
+		return nil
+	}
+
+	i := 0
+	if sess == s.players[1] {
+		i = 1
+	}
+
+	return &game.SeriesScore{
+		BestOf:       s.bestOf,
+		Game:         min(s.game(), s.bestOf),
+		Wins:         s.wins[i],
+		OpponentWins: s.wins[1-i],
+	}
+}
+
+// score reads like "alice 2 - 1 bob"
+func (s *series) score() string {
+	return fmt.Sprintf("%s %d - %d %s", s.players[0].player.Name, s.wins[0], s.wins[1], s.players[1].player.Name)
+}
+
+// seriesResult counts a finished match and tells both players how to play on
+func (h *hub) seriesResult(m *match, winner *session) {
+	s := m.series
+	s.wins[s.index(winner)]++
+	s.offer = nil
+
+	if s.cup != nil {
+		h.boutGame(m, winner)
+		return
+	}
+
+	// nobody to play on with once a player has left the server
+	for _, sess := range m.players {
+		if sess.bot == nil && h.sessions[sess.token] != sess {
+			return
+		}
+	}
+
+	switch {
+	case s.bestOf < 2:
+		m.players[0].notice("REMATCH", "/rematch to play "+m.players[1].player.Name+" again")
+		m.players[1].notice("REMATCH", "/rematch to play "+m.players[0].player.Name+" again")
+
+	case s.decided():
+		m.notice("SERIES_OVER", fmt.Sprintf("%s wins the best of %d series, %s. /rematch to start a new one", winner.player.Name, s.bestOf, s.score()))
+
+	default:
+		m.notice("SERIES", fmt.Sprintf("%s in a best of %d. /rematch to play game %d", s.score(), s.bestOf, s.game()))
+	}
+}
+
+// handleRematch offers your last opponent another match, or accepts their offer,
+// /rematch [best-of]. A best-of only applies to a new series.
+func (h *hub) handleRematch(sess *session, args []string) protocol.Message {
+	if h.findMatch(sess) != nil {
+		return errorMsg(protocol.CodeAlreadyInGame, "Finish this match first")
+	}
+
+	s := sess.series
+	if s == nil {
+		return errorMsg(protocol.CodeNoRematch, "Nobody to rematch, play a match first")
+	}
+
+	if s.cup != nil {
+		return errorMsg(protocol.CodeNoRematch, "Tournament matches are drawn by the bracket, see /bracket")
+	}
+
+	other := s.opponentOf(sess)
+
+	bestOf := s.bestOf
+	if s.offer == other {
+		bestOf = s.offerBestOf // taking up their offer as it stands
+	}
+
+	if len(args) > 0 {
+		n, err := strconv.Atoi(strings.TrimPrefix(strings.ToLower(args[0]), "bo"))
+		if err != nil || n < 1 || n%2 == 0 {
+			return errorMsg(protocol.CodeUsage, "Usage: /rematch [best-of], an odd number of games such as 3 or bo5")
+		}
+
+		if !s.decided() && n != s.bestOf {
+			return errorMsg(protocol.CodeUsage, fmt.Sprintf("This series is a best of %d until it's decided, %s", s.bestOf, s.score()))
+		}
+		bestOf = n
+	}
+
+	switch {
+	case sess.room != nil:
+		return errorMsg(protocol.CodeInRoom, "You're in room "+sess.room.code+", use /leave-room first")
+	case h.entered(sess):
+		return errorMsg(protocol.CodeNoRematch, "You're entered in "+h.cup.bracket.Name+", /tournament leave first")
+	case other.room != nil:
+		return errorMsg(protocol.CodeNoRematch, other.player.Name+" has moved on to a room")
+	case h.entered(other):
+		return errorMsg(protocol.CodeNoRematch, other.player.Name+" has entered "+h.cup.bracket.Name)
+	case other.series != s || h.findMatch(other) != nil:
+		return errorMsg(protocol.CodeNoRematch, other.player.Name+" has moved on to another match")
+	case other.bot == nil && other.client == nil:
+		return errorMsg(protocol.CodeNoRematch, other.player.Name+" isn't connected")
+	}
+
+	// agreeing to the same terms starts it, anything else is a (counter) offer
+	if s.offer == other && s.offerBestOf == bestOf {
+		h.rematch(s, bestOf)
+		return nil
+	}
+
+	if s.offer == sess && s.offerBestOf == bestOf {
+		return notice("REMATCH_OFFERED", "Still waiting for "+other.player.Name+" to accept")
+	}
+
+	s.offer, s.offerBestOf = sess, bestOf
+
+	// computers always want another go
+	if other.bot != nil {
+		h.rematch(s, bestOf)
+		return nil
+	}
+
+	terms := ""
+	if s.decided() && bestOf > 1 {
+		terms = fmt.Sprintf(" (a best of %d series)", bestOf)
+	}
+
+	other.notice("REMATCH_OFFER", sess.player.Name+" wants a rematch"+terms+", /rematch to accept")
+	return notice("REMATCH_OFFERED", "Rematch offered to "+other.player.Name+terms+", waiting for them to accept")
+}
+
+// leaveSeries drops sess's last series once they move on to something else.
+// Their opponent can't rematch any more and goes back to the lobby.
+func (h *hub) leaveSeries(sess *session) {
+	s := sess.series
+	if s == nil || s.cup != nil || h.findMatch(sess) != nil {
+		return
+	}
+	sess.series = nil
+
+	if other := s.opponentOf(sess); other.series == s && h.findMatch(other) == nil {
+		other.notice("GAME_RESET", "")
+	}
+}
+
+// rematch plays the next match of s, or starts a new series of bestOf once s
+// is decided. Whoever fired second last time fires first.
+func (h *hub) rematch(s *series, bestOf int) {
+	first := s.opponentOf(s.first)
+	second := s.first
+
+	for _, sess := range s.players {
+		h.dequeue(sess)
+	}
+
+	if s.decided() {
+		s = newSeries(first, second, s.rules, bestOf)
+	}
+	s.offer = nil
+
+	h.startSeriesMatch(first, second, s, true)
+}
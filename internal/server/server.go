@@ -0,0 +1,169 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/ahmaruff/go-fleet/internal/game"
+	"github.com/ahmaruff/go-fleet/internal/protocol"
+	"github.com/ahmaruff/go-fleet/internal/store"
+)
+
+type Config struct {
+	Rules game.Rules
+	// how long a match is held for a disconnected player, 0 forfeits right away
+	ReconnectGrace time.Duration
+	ReplayDir      string // where finished matches are saved, empty disables replays
+	// spectators see both fleets instead of only the shots fired
+	SpectatorReveal bool
+	Store           store.Store // player accounts, nil leaves everyone a guest
+
+	// random matchmaking pairs players at most MatchWindow rating points apart,
+	// widening by MatchWindowGrowth for every second waited. 0 pairs strictly first come, first served.
+	MatchWindow       int
+	MatchWindowGrowth int
+
+	// deadlines for placing the whole fleet and for each shot, 0 for no limit
+	PlacementTime time.Duration
+	TurnTime      time.Duration
+	TimeoutPolicy TimeoutPolicy // what an expired turn costs, skip when empty
+	ForfeitAfter  int           // timeouts before a forfeit under TimeoutForfeit
+
+	BestOf int      // games in a series of /rematch games, 0 or 1 for single matches
+	Admins []string // registered players who may run tournaments
+	Quiet  bool     // suppress [SERVER] logs, used by simulations
+}
+
+// Server accepts connections and hands them to the hub, which owns every
+// session and game. Connection goroutines never touch shared state directly.
+type Server struct {
+	config Config
+	hub    *hub
+}
+
+func New(config Config) *Server {
+	s := Server{
+		config: config,
+		hub:    newHub(config),
+	}
+
+	go s.hub.run()
+
+	return &s
+}
+
+func (s *Server) Serve(listener net.Listener) error {
+	for {
+		// Accept incoming connections
+		conn, err := listener.Accept()
+		if err != nil {
+			select {
+			case <-s.hub.quit:
+				return nil
+			default:
+			}
+
+			s.hub.logf("[SERVER] Failed to accept connection: %v\n", err)
+			if ne, ok := err.(net.Error); ok && ne.Timeout() {
+				continue
+			}
+			return err
+		}
+
+		s.hub.logf("[SERVER] New client connected!\n")
+
+		// Handle each client in a separate goroutine
+		go s.HandleConn(conn)
+	}
+}
+
+// how long a new connection has to complete the handshake
+const handshakeTimeout = 10 * time.Second
+
+// HandleConn runs the handshake, then reads commands from conn until it disconnects
+func (s *Server) HandleConn(conn net.Conn) {
+	defer conn.Close()
+
+	decoder := protocol.NewDecoder(conn)
+
+	hello, version, err := handshake(conn, decoder)
+	if err != nil {
+		s.hub.logf("[SERVER] Handshake failed: %v\n", err)
+		return
+	}
+
+	c := newClient(conn, version)
+	go c.writeLoop()
+
+	if !s.hub.post(event{kind: eventJoin, client: c, token: hello.Token}) {
+		return
+	}
+
+	for {
+		m, err := decoder.Decode()
+		if errors.Is(err, protocol.ErrMalformed) {
+			// a garbled line costs only that line, the stream stays framed
+			s.hub.post(event{kind: eventInvalid, client: c, err: err})
+			continue
+		}
+
+		if err != nil {
+			s.hub.logf("[SERVER] Client disconnected\n")
+			s.hub.post(event{kind: eventLeave, client: c})
+			return
+		}
+
+		command, ok := m.(protocol.Command)
+		if !ok {
+			s.hub.post(event{kind: eventInvalid, client: c, err: fmt.Errorf("unexpected %s message", m.MessageType())})
+			continue
+		}
+
+		s.hub.post(event{kind: eventCommand, client: c, command: command})
+	}
+}
+
+// handshake expects Hello as the first message and answers with the negotiated version
+func handshake(conn net.Conn, decoder *protocol.Decoder) (protocol.Hello, int, error) {
+	encoder := protocol.NewEncoder(conn)
+
+	conn.SetReadDeadline(time.Now().Add(handshakeTimeout))
+	defer conn.SetReadDeadline(time.Time{})
+
+	m, err := decoder.Decode()
+	if err != nil {
+		return protocol.Hello{}, 0, err
+	}
+
+	hello, ok := m.(protocol.Hello)
+	if !ok {
+		encoder.Encode(protocol.Error{Code: protocol.CodeBadRequest, Text: "expected hello"})
+		return protocol.Hello{}, 0, fmt.Errorf("expected hello, got %s", m.MessageType())
+	}
+
+	version, ok := protocol.Negotiate(hello)
+	if !ok {
+		encoder.Encode(protocol.Error{
+			Code: protocol.CodeUnsupportedVersion,
+			Text: fmt.Sprintf("server speaks protocol %d to %d", protocol.MinVersion, protocol.Version),
+		})
+		return hello, 0, fmt.Errorf("no common protocol version with client %d..%d", hello.MinVersion, hello.MaxVersion)
+	}
+
+	return hello, version, encoder.Encode(protocol.Welcome{Version: version})
+}
+
+// Close stops the hub, connections still open are left to their owners
+func (s *Server) Close() {
+	close(s.hub.quit)
+}
+
+func (h *hub) logf(format string, args ...any) {
+	if h.config.Quiet {
+		return
+	}
+
+	fmt.Printf(format, args...)
+}
@@ -0,0 +1,443 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ahmaruff/go-fleet/internal/game"
+	"github.com/ahmaruff/go-fleet/internal/protocol"
+)
+
+// testClient is a scripted player on one end of a net.Pipe, the server holds the other
+type testClient struct {
+	conn     net.Conn
+	encoder  *protocol.Encoder
+	messages chan protocol.Message
+}
+
+// connect hands srv a fresh pipe and completes the handshake on our end
+func connect(t *testing.T, srv *Server) *testClient {
+	t.Helper()
+
+	clientConn, serverConn := net.Pipe()
+	go srv.HandleConn(serverConn)
+
+	c := &testClient{
+		conn:     clientConn,
+		encoder:  protocol.NewEncoder(clientConn),
+		messages: make(chan protocol.Message, 64),
+	}
+	decoder := protocol.NewDecoder(clientConn)
+
+	if _, err := protocol.ClientHandshake(c.encoder, decoder, ""); err != nil {
+		clientConn.Close()
+		t.Fatalf("handshake: %v", err)
+	}
+
+	go func() {
+		defer close(c.messages)
+		for {
+			m, err := decoder.Decode()
+			if err != nil {
+				return
+			}
+			c.messages <- m
+		}
+	}()
+
+	t.Cleanup(c.close)
+	return c
+}
+
+// close hangs up and drains whatever the server still had queued for us
+func (c *testClient) close() {
+	c.conn.Close()
+	for range c.messages {
+	}
+}
+
+func (c *testClient) send(line string) error {
+	command, _ := protocol.ParseCommand(line)
+	return c.encoder.Encode(command)
+}
+
+// expect waits for a notice tagged with one of tags, skipping everything else.
+// The tag "ERROR" matches any error reply and "STATE" any board update.
+func (c *testClient) expect(timeout time.Duration, tags ...string) (string, error) {
+	deadline := time.After(timeout)
+	for {
+		select {
+		case m, ok := <-c.messages:
+			if !ok {
+				return "", fmt.Errorf("connection closed while waiting for %v", tags)
+			}
+
+			tag := ""
+			switch msg := m.(type) {
+			case protocol.Notice:
+				tag = msg.Tag
+			case protocol.Error:
+				tag = "ERROR"
+			case protocol.State:
+				tag = "STATE"
+			}
+
+			if slices.Contains(tags, tag) {
+				return tag, nil
+			}
+		case <-deadline:
+			return "", fmt.Errorf("timed out waiting for %v", tags)
+		}
+	}
+}
+
+// position waits for the next queue notice and returns the place it gives
+func (c *testClient) position(timeout time.Duration) (string, error) {
+	deadline := time.After(timeout)
+	for {
+		select {
+		case m, ok := <-c.messages:
+			if !ok {
+				return "", fmt.Errorf("connection closed while waiting for a queue position")
+			}
+
+			if msg, ok := m.(protocol.Notice); ok && msg.Tag == "WAITING" {
+				_, place, _ := strings.Cut(msg.Text, "(position ")
+				return strings.TrimSuffix(place, " in queue)"), nil
+			}
+		case <-deadline:
+			return "", fmt.Errorf("timed out waiting for a queue position")
+		}
+	}
+}
+
+// command sends line and waits for one of tags in reply
+func (c *testClient) command(line string, tags ...string) (string, error) {
+	if err := c.send(line); err != nil {
+		return "", err
+	}
+	return c.expect(5*time.Second, tags...)
+}
+
+// enter names the player, queues them up and places their fleet down the left edge
+func (c *testClient) enter(name string) error {
+	if _, err := c.command("/name "+name, "NAME_SET"); err != nil {
+		return err
+	}
+	if _, err := c.command("/ready", "GAME_START"); err != nil {
+		return err
+	}
+
+	for i, class := range game.Fleet {
+		if _, err := c.command(fmt.Sprintf("/place %s A%d H", class.Name, i*2+1), "SHIP_PLACED"); err != nil {
+			return err
+		}
+	}
+
+	_, err := c.command("/confirm", "FLEET_CONFIRMED")
+	return err
+}
+
+// play fires at every cell in turn until the match is over, each shot as soon
+// as the latest board says it's our turn
+func (c *testClient) play() error {
+	cell, yourTurn, pending := 0, false, false
+
+	for cell < game.DefaultBoardSize*game.DefaultBoardSize {
+		select {
+		case m, ok := <-c.messages:
+			if !ok {
+				return fmt.Errorf("connection closed mid-match")
+			}
+
+			switch msg := m.(type) {
+			case protocol.State:
+				yourTurn = msg.View.Phase == game.PhasePlaying && msg.View.YourTurn
+			case protocol.Error:
+				// a board that was already stale, wait for the next one
+				pending = false
+			case protocol.Notice:
+				switch msg.Tag {
+				case "SHOT_RESULT":
+					cell++
+					pending = false
+				case "GAME_OVER":
+					return nil
+				case "OPPONENT_DISCONNECTED":
+					return fmt.Errorf("opponent disconnected")
+				}
+			}
+		case <-time.After(30 * time.Second):
+			return fmt.Errorf("timed out waiting for a turn at shot %d", cell)
+		}
+
+		if yourTurn && !pending && cell < game.DefaultBoardSize*game.DefaultBoardSize {
+			if err := c.send("/fire " + game.CellName(cell/game.DefaultBoardSize, cell%game.DefaultBoardSize)); err != nil {
+				return err
+			}
+			pending = true
+		}
+	}
+
+	return fmt.Errorf("ran out of cells without a winner")
+}
+
+func TestConcurrentMatches(t *testing.T) {
+	srv := New(Config{Rules: game.DefaultRules(), Quiet: true})
+	defer srv.Close()
+
+	n := 200
+	if testing.Short() {
+		n = 4
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		c := connect(t, srv)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			err := c.enter(fmt.Sprintf("bot-%d", i))
+			if err == nil {
+				err = c.play()
+			}
+			if err != nil {
+				t.Errorf("bot-%d: %v", i, err)
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+func TestDisconnectEndsMatch(t *testing.T) {
+	replays := t.TempDir()
+	srv := New(Config{Rules: game.DefaultRules(), ReplayDir: replays, Quiet: true})
+	defer srv.Close()
+
+	first, second := connect(t, srv), connect(t, srv)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 2)
+	for i, c := range []*testClient{first, second} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := c.enter(fmt.Sprintf("bot-%d", i)); err != nil {
+				errs <- fmt.Errorf("bot-%d: %w", i, err)
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Fatal(err)
+	}
+
+	// no reconnect grace, leaving mid-battle forfeits on the spot
+	if _, err := second.expect(5*time.Second, "COMBAT_START"); err != nil {
+		t.Fatal(err)
+	}
+	first.conn.Close()
+
+	if tag, err := second.expect(5*time.Second, "GAME_OVER", "OPPONENT_DISCONNECTED"); err != nil || tag != "GAME_OVER" {
+		t.Fatalf("got %q, %v before the forfeit was announced", tag, err)
+	}
+	if _, err := second.expect(5*time.Second, "OPPONENT_DISCONNECTED"); err != nil {
+		t.Fatal(err)
+	}
+
+	// a forfeit is a finished game, saved like any other
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		entries, err := os.ReadDir(replays)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d replays saved, want 1", len(entries))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if _, err := second.command("/ready", "WAITING"); err != nil {
+		t.Fatalf("back in the queue after a forfeit: %v", err)
+	}
+}
+
+func TestRematchOfferKeepsBoard(t *testing.T) {
+	srv := New(Config{Rules: game.DefaultRules(), Quiet: true})
+	defer srv.Close()
+
+	players := []*testClient{connect(t, srv), connect(t, srv)}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 2)
+	for i, c := range players {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			err := c.enter(fmt.Sprintf("bot-%d", i))
+			if err == nil {
+				err = c.play()
+			}
+			if err == nil {
+				// the offer comes while the final board is still up
+				var tag string
+				tag, err = c.expect(5*time.Second, "REMATCH", "GAME_RESET")
+				if err == nil && tag != "REMATCH" {
+					err = fmt.Errorf("sent back to the lobby before the rematch offer")
+				}
+			}
+			if err != nil {
+				errs <- fmt.Errorf("bot-%d: %w", i, err)
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Fatal(err)
+	}
+
+	// moving on to a room ends the rematch
+	if _, err := players[1].command("/create-room", "ROOM_CREATED"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := players[0].expect(5*time.Second, "GAME_RESET"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := players[0].command("/rematch", "ERROR"); err != nil {
+		t.Fatalf("rematch into a room: %v", err)
+	}
+}
+
+func TestQueuePositionUpdates(t *testing.T) {
+	srv := New(Config{Rules: game.DefaultRules(), Quiet: true})
+	defer srv.Close()
+
+	// every mode waits alone until the last classic player turns up
+	players := make([]*testClient, 4)
+	for i, mode := range []string{"salvo", "classic", "advanced", "classic"} {
+		players[i] = connect(t, srv)
+		if _, err := players[i].command(fmt.Sprintf("/name bot-%d", i), "NAME_SET"); err != nil {
+			t.Fatal(err)
+		}
+		if i == 3 {
+			break
+		}
+
+		if err := players[i].send("/ready " + mode); err != nil {
+			t.Fatal(err)
+		}
+		if place, err := players[i].position(5 * time.Second); err != nil || place != fmt.Sprintf("%d of %d", i+1, i+1) {
+			t.Fatalf("bot-%d queued at %q, %v", i, place, err)
+		}
+	}
+
+	// the front of the queue leaves
+	if _, err := players[0].command("/cancel", "QUEUE_LEFT"); err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range map[int]string{1: "1 of 2", 2: "2 of 2"} {
+		if place, err := players[i].position(5 * time.Second); err != nil || place != want {
+			t.Fatalf("bot-%d moved up to %q, %v, want %q", i, place, err, want)
+		}
+	}
+
+	// the classic player ahead is paired
+	if _, err := players[3].command("/ready classic", "GAME_START"); err != nil {
+		t.Fatal(err)
+	}
+	if place, err := players[2].position(5 * time.Second); err != nil || place != "1 of 1" {
+		t.Fatalf("bot-2 moved up to %q, %v, want \"1 of 1\"", place, err)
+	}
+}
+
+func TestMatchmakeIdlesAcrossModes(t *testing.T) {
+	h := newHub(Config{Rules: game.DefaultRules(), MatchWindow: 100, MatchWindowGrowth: 10, Quiet: true})
+	now := time.Now()
+
+	salvo, classic := h.config.Rules, h.config.Rules
+	salvo.Mode = game.ModeSalvo
+
+	h.queue.add(&session{rating: 1200}, salvo, now)
+	h.queue.add(&session{rating: 1200}, classic, now)
+	h.matchmake()
+
+	if h.matchmakeTimer != nil {
+		t.Fatal("matchmaking timer armed with nobody to pair")
+	}
+
+	// far apart in rating, the window widens until they meet
+	h.queue.add(&session{rating: 2000}, classic, now)
+	h.matchmake()
+
+	if h.matchmakeTimer == nil {
+		t.Fatal("matchmaking timer not armed for two classic players")
+	}
+	h.matchmakeTimer.Stop()
+}
+
+func TestLoggedArgsMaskPasswords(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+	}{
+		{"/login ann hunter2", "ann ***"},
+		{"/REGISTER ann hunter2", "ann ***"},
+		{"/join K3X9 secret", "K3X9 ***"},
+		{"/create-room Lunch room --password secret --salvo", "Lunch room --password *** --salvo"},
+		{"/fire B3", "B3"},
+		{"/say my password is fine", "my password is fine"},
+	}
+
+	for _, tt := range tests {
+		command, _ := protocol.ParseCommand(tt.line)
+		if got := strings.Join(loggedArgs(command), " "); got != tt.want {
+			t.Errorf("loggedArgs(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestHandshakeVersionMismatch(t *testing.T) {
+	srv := New(Config{Rules: game.DefaultRules(), Quiet: true})
+	defer srv.Close()
+
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+	go srv.HandleConn(serverConn)
+
+	// a client from the future, nothing in common with this server
+	hello := protocol.Hello{MinVersion: protocol.Version + 1, MaxVersion: protocol.Version + 2}
+	if err := protocol.NewEncoder(clientConn).Encode(hello); err != nil {
+		t.Fatal(err)
+	}
+
+	decoder := protocol.NewDecoder(clientConn)
+	reply, err := decoder.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e, ok := reply.(protocol.Error); !ok || e.Code != protocol.CodeUnsupportedVersion {
+		t.Fatalf("got %#v, want an %s error", reply, protocol.CodeUnsupportedVersion)
+	}
+
+	// and the server hangs up
+	if _, err := decoder.Decode(); err == nil {
+		t.Error("connection still open after a failed handshake")
+	}
+}
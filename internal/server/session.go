@@ -0,0 +1,58 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/ahmaruff/go-fleet/internal/ai"
+	"github.com/ahmaruff/go-fleet/internal/game"
+	"github.com/ahmaruff/go-fleet/internal/protocol"
+)
+
+// session is a named player, it outlives the connection that created it
+// for as long as the reconnect grace period allows
+type session struct {
+	token  string
+	player *game.Player
+	client *client // nil while disconnected and waiting to be resumed, always nil for bots
+	bot    ai.Strategy
+
+	registered bool // logged in to an account, guests picked their name with /name
+	rating     int  // matchmaking rating, guests sit at rating.Initial
+
+	watching *match // the match this session spectates, if any
+	room     *room
+
+	ignoring map[string]string // names whose chat is dropped, keyed in lower case
+	series   *series           // the latest series played, for /rematch
+
+	grace *time.Timer // running while disconnected
+}
+
+func newToken() string {
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+// send drops the message while disconnected, a resumed client gets a full resync instead
+func (s *session) send(m protocol.Message) {
+	if s.client == nil {
+		return
+	}
+
+	s.client.send(m)
+}
+
+func (s *session) notice(tag, text string) {
+	s.send(protocol.Notice{Tag: tag, Text: text})
+}
+
+func (s *session) effect(name string) {
+	s.send(protocol.Effect{Name: name})
+}
+
+func (s *session) state(view game.View) {
+	s.send(protocol.State{View: view})
+}
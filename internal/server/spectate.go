@@ -0,0 +1,87 @@
+package server
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ahmaruff/go-fleet/internal/protocol"
+)
+
+// handleGames lists the matches in progress, /games
+func (h *hub) handleGames() protocol.Message {
+	if len(h.matches) == 0 {
+		return notice("GAMES", "No matches in progress")
+	}
+
+	ids := make([]int, 0, len(h.matches))
+	for id := range h.matches {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	var list strings.Builder
+	list.WriteString("Matches in progress, /watch <id> to spectate:")
+	for _, id := range ids {
+		m := h.matches[id]
+		list.WriteString(fmt.Sprintf("\n  #%d %s vs %s - %s", id, m.players[0].player.Name, m.players[1].player.Name, m.game.Phase))
+		if len(m.spectators) > 0 {
+			list.WriteString(fmt.Sprintf(" (%d watching)", len(m.spectators)))
+		}
+	}
+
+	return notice("GAMES", list.String())
+}
+
+// handleWatch joins a match as a read-only spectator, /watch <id>
+func (h *hub) handleWatch(sess *session, args []string) protocol.Message {
+	if len(args) < 1 {
+		return errorMsg(protocol.CodeUsage, "Usage: /watch <id> (see /games)")
+	}
+
+	id, err := strconv.Atoi(strings.TrimPrefix(args[0], "#"))
+	if err != nil {
+		return errorMsg(protocol.CodeUsage, "Usage: /watch <id> (see /games)")
+	}
+
+	if h.findMatch(sess) != nil {
+		return errorMsg(protocol.CodeAlreadyInGame, "You can't watch while playing a match")
+	}
+
+	m := h.matches[id]
+	if m == nil {
+		return errorMsg(protocol.CodeNoSuchGame, fmt.Sprintf("No match #%d, see /games", id))
+	}
+
+	if sess.watching == m {
+		return notice("WATCHING", fmt.Sprintf("Already watching #%d", id))
+	}
+
+	if sess.watching != nil {
+		sess.watching.removeSpectator(sess)
+	}
+
+	// spectators aren't looking for a match
+	h.dequeue(sess)
+
+	m.spectators = append(m.spectators, sess)
+	sess.watching = m
+
+	m.players[0].notice("SPECTATOR_JOINED", sess.player.Name+" is watching")
+	m.players[1].notice("SPECTATOR_JOINED", sess.player.Name+" is watching")
+
+	sess.state(h.viewFor(m, sess))
+
+	return notice("WATCHING", fmt.Sprintf("Watching #%d %s vs %s, /unwatch to stop", id, m.players[0].player.Name, m.players[1].player.Name))
+}
+
+func (h *hub) handleUnwatch(sess *session) protocol.Message {
+	if sess.watching == nil {
+		return errorMsg(protocol.CodeNotInGame, "You're not watching a match")
+	}
+
+	sess.watching.removeSpectator(sess)
+
+	return notice("WATCH_END", "Stopped watching")
+}
@@ -0,0 +1,411 @@
+package server
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ahmaruff/go-fleet/internal/game"
+	"github.com/ahmaruff/go-fleet/internal/protocol"
+	"github.com/ahmaruff/go-fleet/internal/tournament"
+)
+
+// pause between the games of a tournament match, long enough to read the result
+const boutPause = 5 * time.Second
+
+// cup is the tournament the hub runs, one at a time. Entrants are registered
+// players known by their account name, one who drops out is held for
+// ReconnectGrace and keeps their place if they're back in time.
+type cup struct {
+	bracket *tournament.Tournament
+	admin   string
+	rules   game.Rules
+	bestOf  int // games in each tournament match
+}
+
+// isAdmin reports whether sess may run tournaments: a registered player named in Config.Admins
+func (h *hub) isAdmin(sess *session) bool {
+	return sess.registered && slices.ContainsFunc(h.config.Admins, func(name string) bool {
+		return strings.EqualFold(name, sess.player.Name)
+	})
+}
+
+// handleTournament runs the /tournament subcommands, without one it shows where the tournament stands
+func (h *hub) handleTournament(sess *session, args []string) protocol.Message {
+	if len(args) == 0 {
+		return h.tournamentInfo()
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "create":
+		return h.createTournament(sess, args[1:])
+	case "join":
+		return h.joinTournament(sess)
+	case "leave":
+		return h.leaveTournament(sess)
+	case "start":
+		return h.startTournament(sess)
+	case "cancel":
+		return h.cancelTournament(sess)
+	default:
+		return errorMsg(protocol.CodeUsage, "Usage: /tournament [create|join|leave|start|cancel]")
+	}
+}
+
+func (h *hub) tournamentInfo() protocol.Message {
+	c := h.cup
+	if c == nil {
+		return notice("TOURNAMENT", "No tournament running, an admin can open one with /tournament create <single|double|round-robin> [name]")
+	}
+
+	t := c.bracket
+	text := fmt.Sprintf("%s: %s run by %s, %d entered, %s a match", t.Name, t.Format, c.admin, len(t.Players), describeBout(c))
+
+	switch {
+	case t.Over():
+		text += fmt.Sprintf(". %s won it", t.Champion())
+	case t.Started:
+		text += ". Under way, see /bracket"
+	default:
+		text += ". Open for entries, /tournament join to play"
+	}
+
+	return notice("TOURNAMENT", text)
+}
+
+// describeBout reads like "best of 3 salvo games" or "one classic game"
+func describeBout(c *cup) string {
+	if c.bestOf > 1 {
+		return fmt.Sprintf("best of %d %s games", c.bestOf, c.rules.Mode)
+	}
+	return fmt.Sprintf("one %s game", c.rules.Mode)
+}
+
+// createTournament opens a tournament for entries,
+// /tournament create <format> [name] [--bo N] [--salvo|--advanced]
+func (h *hub) createTournament(sess *session, args []string) protocol.Message {
+	usage := errorMsg(protocol.CodeUsage, "Usage: /tournament create <single|double|round-robin> [name] [--bo N] [--salvo|--advanced]")
+
+	if !h.isAdmin(sess) {
+		return errorMsg(protocol.CodeNotAdmin, "Only an admin can create a tournament")
+	}
+
+	if h.cup != nil && !h.cup.bracket.Over() {
+		return errorMsg(protocol.CodeWrongPhase, h.cup.bracket.Name+" isn't over yet, /tournament cancel it first")
+	}
+
+	rules, args := h.pickMode(args)
+	if len(args) == 0 {
+		return usage
+	}
+
+	format, err := tournament.ParseFormat(args[0])
+	if err != nil {
+		return errorMsg(protocol.CodeUsage, "Cannot create a tournament: "+err.Error())
+	}
+
+	bestOf := 1
+	var name []string
+	for i := 1; i < len(args); i++ {
+		if args[i] != "--bo" {
+			name = append(name, args[i])
+			continue
+		}
+
+		if i+1 == len(args) {
+			return usage
+		}
+		i++
+
+		bestOf, err = strconv.Atoi(args[i])
+		if err != nil || bestOf < 1 || bestOf%2 == 0 {
+			return errorMsg(protocol.CodeUsage, "--bo takes an odd number of games, such as 3")
+		}
+	}
+
+	if len(name) == 0 {
+		name = []string{"Tournament"}
+	}
+
+	c := &cup{
+		bracket: tournament.New(cleanText(strings.Join(name, " ")), format),
+		admin:   sess.player.Name,
+		rules:   rules,
+		bestOf:  bestOf,
+	}
+	h.cup = c
+
+	// everyone online may want in
+	for _, other := range h.clients {
+		other.notice("TOURNAMENT", fmt.Sprintf("%s opened %s: %s, %s a match. /tournament join to play", sess.player.Name, c.bracket.Name, format, describeBout(c)))
+	}
+
+	return nil
+}
+
+func (h *hub) joinTournament(sess *session) protocol.Message {
+	c := h.cup
+	if c == nil || c.bracket.Over() {
+		return errorMsg(protocol.CodeNoTournament, "No tournament is open for entries")
+	}
+
+	if !sess.registered {
+		return errorMsg(protocol.CodeNameRequired, "Only registered players can enter a tournament, /register or /login first")
+	}
+
+	if err := c.bracket.Register(sess.player.Name); err != nil {
+		return errorMsg(protocol.CodeWrongPhase, "Cannot join "+c.bracket.Name+": "+err.Error())
+	}
+
+	h.leaveSeries(sess)
+
+	h.tournamentNotice(c, fmt.Sprintf("%s joined %s, %d entered so far", sess.player.Name, c.bracket.Name, len(c.bracket.Players)))
+	return nil
+}
+
+// leaveTournament takes an entry back before the start, or withdraws from a
+// tournament under way, handing every match left to the opponent
+func (h *hub) leaveTournament(sess *session) protocol.Message {
+	c := h.cup
+	if c == nil || !c.bracket.Registered(sess.player.Name) || c.bracket.Withdrawn(sess.player.Name) {
+		return errorMsg(protocol.CodeNoTournament, "You're not in a tournament")
+	}
+
+	if m := h.findMatch(sess); m != nil && m.series.cup == c {
+		return errorMsg(protocol.CodeAlreadyInGame, "Finish your tournament match first")
+	}
+
+	h.tournamentNotice(c, sess.player.Name+" left "+c.bracket.Name)
+	h.withdraw(c, sess.player.Name)
+
+	return notice("TOURNAMENT", "You left "+c.bracket.Name)
+}
+
+// startTournament draws the bracket and starts every match it can,
+// registered players are seeded by rating
+func (h *hub) startTournament(sess *session) protocol.Message {
+	c := h.cup
+	if c == nil {
+		return errorMsg(protocol.CodeNoTournament, "No tournament to start, /tournament create one first")
+	}
+
+	if !h.isAdmin(sess) {
+		return errorMsg(protocol.CodeNotAdmin, "Only an admin can start the tournament")
+	}
+
+	ratings := make(map[string]int)
+	for _, entrant := range h.clients {
+		ratings[entrant.player.Name] = entrant.rating
+	}
+	slices.SortStableFunc(c.bracket.Players, func(a, b string) int {
+		return ratings[b] - ratings[a]
+	})
+
+	if err := c.bracket.Start(); err != nil {
+		return errorMsg(protocol.CodeWrongPhase, "Cannot start "+c.bracket.Name+": "+err.Error())
+	}
+
+	h.tournamentNotice(c, c.bracket.Name+" is under way! Matches start on their own, /bracket to follow along")
+	h.tournamentProgress(c)
+
+	return nil
+}
+
+func (h *hub) cancelTournament(sess *session) protocol.Message {
+	c := h.cup
+	if c == nil {
+		return errorMsg(protocol.CodeNoTournament, "No tournament to cancel")
+	}
+
+	if !h.isAdmin(sess) {
+		return errorMsg(protocol.CodeNotAdmin, "Only an admin can cancel the tournament")
+	}
+
+	// matches already under way play out, they just don't count
+	h.tournamentNotice(c, c.bracket.Name+" was cancelled")
+	h.cup = nil
+
+	return notice("TOURNAMENT", c.bracket.Name+" cancelled")
+}
+
+// handleBracket draws the tournament, /bracket
+func (h *hub) handleBracket() protocol.Message {
+	if h.cup == nil {
+		return errorMsg(protocol.CodeNoTournament, "No tournament running")
+	}
+
+	return notice("BRACKET", h.cup.bracket.Render())
+}
+
+// tournamentNotice goes to every entrant and the admin who are online
+func (h *hub) tournamentNotice(c *cup, text string) {
+	for _, sess := range h.clients {
+		if c.bracket.Registered(sess.player.Name) || strings.EqualFold(sess.player.Name, c.admin) {
+			sess.notice("TOURNAMENT", text)
+		}
+	}
+}
+
+// scheduleTournament starts every tournament match whose players are both
+// online and free, it runs again whenever a match ends or a player returns
+func (h *hub) scheduleTournament() {
+	c := h.cup
+	if c == nil || !c.bracket.Started {
+		return
+	}
+
+	// a best of N match keeps its players between games
+	busy := make(map[string]bool)
+	for _, bout := range c.bracket.Matches {
+		if bout.Playing {
+			busy[bout.Players[0]], busy[bout.Players[1]] = true, true
+		}
+	}
+
+	for _, bout := range c.bracket.Ready() {
+		if busy[bout.Players[0]] || busy[bout.Players[1]] {
+			continue
+		}
+
+		first, second := h.entrant(bout.Players[0]), h.entrant(bout.Players[1])
+		if first == nil || second == nil || h.findMatch(first) != nil || h.findMatch(second) != nil {
+			continue
+		}
+
+		c.bracket.Begin(bout)
+		busy[bout.Players[0]], busy[bout.Players[1]] = true, true
+		h.dequeue(first)
+		h.dequeue(second)
+
+		s := newSeries(first, second, c.rules, c.bestOf)
+		s.cup, s.bout = c, bout
+
+		m := h.startSeriesMatch(first, second, s, false)
+		m.notice("TOURNAMENT", fmt.Sprintf("%s %s: %s vs %s, %s", c.bracket.Name, c.bracket.RoundName(bout), first.player.Name, second.player.Name, describeBout(c)))
+	}
+}
+
+// entrant finds the online session logged in to the account name
+func (h *hub) entrant(name string) *session {
+	for _, sess := range h.clients {
+		if sess.registered && strings.EqualFold(sess.player.Name, name) {
+			return sess
+		}
+	}
+	return nil
+}
+
+// boutGame follows a finished game of a tournament match: the next game of
+// its series, or the result into the bracket once the series is decided
+func (h *hub) boutGame(m *match, winner *session) {
+	s := m.series
+
+	if s.bestOf > 1 && !s.decided() {
+		m.notice("SERIES", fmt.Sprintf("%s in a best of %d, game %d starts in %s", s.score(), s.bestOf, s.game(), boutPause))
+		h.after(boutPause, func() { h.nextBoutGame(s) })
+		return
+	}
+
+	// the bout is settled, no game follows between these two
+	for _, sess := range m.players {
+		sess.notice("GAME_RESET", "")
+	}
+
+	if h.cup != s.cup {
+		return // cancelled while it was played
+	}
+
+	if err := s.cup.bracket.Report(s.bout.ID, winner.player.Name); err != nil {
+		return // settled by a withdrawal in the meantime
+	}
+
+	result := fmt.Sprintf("%s beat %s (%s)", winner.player.Name, s.opponentOf(winner).player.Name, s.cup.bracket.RoundName(s.bout))
+	if s.bestOf > 1 {
+		result += ", " + s.score()
+	}
+	h.tournamentNotice(s.cup, result)
+
+	h.tournamentProgress(s.cup)
+}
+
+// nextBoutGame plays on in a tournament series once both players are online
+// and free, whoever fired second last time fires first
+func (h *hub) nextBoutGame(s *series) {
+	if h.cup != s.cup || s.bout.Done {
+		return // cancelled, or a player withdrew
+	}
+
+	for _, sess := range s.players {
+		if sess.client == nil || h.findMatch(sess) != nil {
+			h.after(boutPause, func() { h.nextBoutGame(s) })
+			return
+		}
+	}
+
+	first := s.opponentOf(s.first)
+	for _, sess := range s.players {
+		h.dequeue(sess)
+	}
+
+	h.startSeriesMatch(first, s.opponentOf(first), s, true)
+}
+
+// withdraw takes name out of the tournament, their opponents walk over
+func (h *hub) withdraw(c *cup, name string) {
+	if !c.bracket.Started {
+		c.bracket.Unregister(name)
+		return
+	}
+
+	c.bracket.Withdraw(name)
+	h.tournamentProgress(c)
+}
+
+// tournamentProgress crowns the champion once the last match is decided,
+// until then it starts whatever can be played next
+func (h *hub) tournamentProgress(c *cup) {
+	if h.cup != c {
+		return
+	}
+
+	if !c.bracket.Over() {
+		h.scheduleTournament()
+		return
+	}
+
+	champion := c.bracket.Champion()
+	if champion == "" {
+		h.tournamentNotice(c, c.bracket.Name+" is over, nobody was left to win it")
+		return
+	}
+
+	h.tournamentNotice(c, champion+" wins "+c.bracket.Name+"!")
+}
+
+// entered reports whether sess holds an entry in the tournament, drawn or not
+func (h *hub) entered(sess *session) bool {
+	c := h.cup
+	return c != nil && !c.bracket.Over() && sess.registered &&
+		c.bracket.Registered(sess.player.Name) && !c.bracket.Withdrawn(sess.player.Name)
+}
+
+// inTournament reports whether sess still has matches to play in the running tournament
+func (h *hub) inTournament(sess *session) bool {
+	c := h.cup
+	return c != nil && c.bracket.Started && !c.bracket.Over() &&
+		c.bracket.Registered(sess.player.Name) && !c.bracket.Withdrawn(sess.player.Name)
+}
+
+// playerGone withdraws a player who left the server for good, then starts
+// whatever their leaving freed up
+func (h *hub) playerGone(sess *session) {
+	name := sess.player.Name
+	if c := h.cup; c != nil && c.bracket.Registered(name) && !c.bracket.Withdrawn(name) {
+		h.tournamentNotice(c, name+" left the server, withdrawing from "+c.bracket.Name)
+		h.withdraw(c, name)
+	}
+
+	h.scheduleTournament()
+}
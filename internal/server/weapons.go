@@ -0,0 +1,93 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ahmaruff/go-fleet/internal/game"
+	"github.com/ahmaruff/go-fleet/internal/protocol"
+)
+
+// handleRadar sweeps a 3x3 area for ships, /radar C3
+func (h *hub) handleRadar(sess *session, args []string) protocol.Message {
+	if len(args) != 1 {
+		return errorMsg(protocol.CodeUsage, "Usage: /radar C3 (sweeps B2 to D4)")
+	}
+
+	m, errMsg := h.playingMatch(sess)
+	if errMsg != nil {
+		return errMsg
+	}
+
+	contact, err := m.game.Radar(sess.player, args[0])
+	if err != nil {
+		return moveError(err, "Invalid radar sweep at "+args[0])
+	}
+
+	result := "all clear"
+	if contact {
+		result = "ships detected"
+	}
+
+	text := fmt.Sprintf("%s's radar sweep around %s: %s", sess.player.Name, strings.ToUpper(args[0]), result)
+	m.opponentOf(sess).notice("RADAR", text)
+	m.spectatorNotice("RADAR", text)
+
+	return notice("RADAR", fmt.Sprintf("Radar sweep around %s: %s", strings.ToUpper(args[0]), result))
+}
+
+// handleStrike fires at a row segment, /strike C3
+func (h *hub) handleStrike(sess *session, args []string) protocol.Message {
+	if len(args) != 1 {
+		return errorMsg(protocol.CodeUsage, "Usage: /strike C3 (hits B3 to D3)")
+	}
+
+	m, errMsg := h.playingMatch(sess)
+	if errMsg != nil {
+		return errMsg
+	}
+
+	results, err := m.game.Airstrike(sess.player, args[0])
+	if err != nil {
+		return moveError(err, "Invalid airstrike at "+args[0])
+	}
+
+	fireEffect, summary := m.reportShots(sess, results)
+	m.effect(fireEffect)
+
+	return notice("SHOT_RESULT", "Airstrike: "+strings.Join(summary, ", "))
+}
+
+// handleTorpedo launches a torpedo that runs until it hits, /torpedo C1 down
+func (h *hub) handleTorpedo(sess *session, args []string) protocol.Message {
+	if len(args) != 2 {
+		return errorMsg(protocol.CodeUsage, "Usage: /torpedo C1 <up|down|left|right>")
+	}
+
+	if _, _, err := game.ParseHeading(args[1]); err != nil {
+		return errorMsg(protocol.CodeUsage, "Usage: /torpedo C1 <up|down|left|right>")
+	}
+
+	m, errMsg := h.playingMatch(sess)
+	if errMsg != nil {
+		return errMsg
+	}
+
+	results, err := m.game.Torpedo(sess.player, args[0], args[1])
+	if err != nil {
+		return moveError(err, "Invalid torpedo at "+args[0])
+	}
+
+	fireEffect, _ := m.reportShots(sess, results)
+	m.effect(fireEffect)
+
+	// only the end of the run matters, the water it crossed shows on the board
+	text := "Torpedo ran off the board"
+	if len(results) > 0 {
+		if last := results[len(results)-1]; last.Outcome != game.Miss {
+			text = "Torpedo: HIT at " + game.CellName(last.Row, last.Col)
+		}
+	}
+
+	return notice("SHOT_RESULT", text)
+}
@@ -0,0 +1,147 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// FileStore keeps every account in one JSON file, loaded into memory on open
+// and rewritten whole on every change
+type FileStore struct {
+	path string
+
+	mu       sync.Mutex
+	accounts map[string]Account // by key(name)
+}
+
+// OpenFile loads the accounts at path, a missing file is an empty store
+func OpenFile(path string) (*FileStore, error) {
+	s := &FileStore{path: path, accounts: make(map[string]Account)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var accounts []Account
+	if err := json.Unmarshal(data, &accounts); err != nil {
+		return nil, err
+	}
+
+	for _, account := range accounts {
+		s.accounts[key(account.Name)] = account
+	}
+
+	return s, nil
+}
+
+func (s *FileStore) Account(name string) (Account, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	account, ok := s.accounts[key(name)]
+	if !ok {
+		return Account{}, ErrNotFound
+	}
+
+	return account, nil
+}
+
+func (s *FileStore) CreateAccount(account Account) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.accounts[key(account.Name)]; exists {
+		return ErrExists
+	}
+
+	s.accounts[key(account.Name)] = account
+	if err := s.save(); err != nil {
+		delete(s.accounts, key(account.Name))
+		return err
+	}
+
+	return nil
+}
+
+func (s *FileStore) UpdateAccounts(accounts ...Account) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous := make(map[string]Account, len(accounts))
+	for _, account := range accounts {
+		stored, exists := s.accounts[key(account.Name)]
+		if !exists {
+			return ErrNotFound
+		}
+		previous[key(account.Name)] = stored
+	}
+
+	for _, account := range accounts {
+		s.accounts[key(account.Name)] = account
+	}
+
+	// one save for the lot, put everything back if it fails
+	if err := s.save(); err != nil {
+		for k, account := range previous {
+			s.accounts[k] = account
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s *FileStore) Accounts() ([]Account, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	accounts := make([]Account, 0, len(s.accounts))
+	for _, account := range s.accounts {
+		accounts = append(accounts, account)
+	}
+
+	return accounts, nil
+}
+
+// save writes to a temporary file first so a crash never leaves half a file behind
+func (s *FileStore) save() error {
+	accounts := make([]Account, 0, len(s.accounts))
+	for _, account := range s.accounts {
+		accounts = append(accounts, account)
+	}
+	slices.SortFunc(accounts, func(a, b Account) int {
+		return strings.Compare(key(a.Name), key(b.Name))
+	})
+
+	data, err := json.MarshalIndent(accounts, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp*")
+	if err != nil {
+		return err
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), s.path)
+}
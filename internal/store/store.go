@@ -0,0 +1,132 @@
+package store
+
+import (
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/ahmaruff/go-fleet/internal/rating"
+)
+
+var (
+	ErrNotFound = errors.New("no such account")
+	ErrExists   = errors.New("account already exists")
+)
+
+// Store keeps player accounts. Implementations must be safe for concurrent use,
+// the server calls them off the hub goroutine.
+type Store interface {
+	// Account looks a player up by name, ignoring case
+	Account(name string) (Account, error)
+	// CreateAccount fails with ErrExists if the name is taken, ignoring case
+	CreateAccount(account Account) error
+	// UpdateAccounts replaces stored accounts all at once: if one doesn't
+	// exist (ErrNotFound) or can't be saved, none of them change
+	UpdateAccounts(accounts ...Account) error
+	// Accounts lists every account, in no particular order
+	Accounts() ([]Account, error)
+}
+
+// password hashing: PBKDF2-SHA256 with a random salt per account
+const (
+	hashIterations = 210_000
+	hashLength     = 32
+	saltLength     = 16
+)
+
+type Account struct {
+	Name    string    `json:"name"`
+	Salt    string    `json:"salt"` // hex
+	Hash    string    `json:"hash"` // hex
+	Created time.Time `json:"created"`
+	Stats   Stats     `json:"stats"`
+}
+
+// Stats are the results of rated matches, those between two registered players
+type Stats struct {
+	Rating   int `json:"rating"`
+	Wins     int `json:"wins"`
+	Losses   int `json:"losses"`
+	Shots    int `json:"shots"`
+	Hits     int `json:"hits"`
+	WinShots int `json:"win_shots"` // shots fired in the matches won
+}
+
+func (s Stats) Played() int {
+	return s.Wins + s.Losses
+}
+
+// Accuracy is the share of shots that hit, 0 to 1
+func (s Stats) Accuracy() float64 {
+	if s.Shots == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Shots)
+}
+
+// AvgShotsToWin is how many shots a won match took on average
+func (s Stats) AvgShotsToWin() float64 {
+	if s.Wins == 0 {
+		return 0
+	}
+	return float64(s.WinShots) / float64(s.Wins)
+}
+
+// NewAccount salts and hashes the password, the password itself is never kept
+func NewAccount(name, password string) (Account, error) {
+	salt := make([]byte, saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return Account{}, err
+	}
+
+	hash, err := hashPassword(password, salt)
+	if err != nil {
+		return Account{}, err
+	}
+
+	return Account{
+		Name:    name,
+		Salt:    hex.EncodeToString(salt),
+		Hash:    hex.EncodeToString(hash),
+		Created: time.Now(),
+		Stats:   Stats{Rating: rating.Initial},
+	}, nil
+}
+
+// NoAccount stands in for a name that has no account. Checking a password
+// against it costs as much as against a real account and always fails, so a
+// failed login doesn't tell whether the name exists.
+var NoAccount = Account{Salt: strings.Repeat("00", saltLength)}
+
+func (a Account) CheckPassword(password string) bool {
+	salt, err := hex.DecodeString(a.Salt)
+	if err != nil {
+		return false
+	}
+
+	want, err := hex.DecodeString(a.Hash)
+	if err != nil {
+		return false
+	}
+
+	got, err := hashPassword(password, salt)
+	if err != nil {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare(got, want) == 1
+}
+
+func hashPassword(password string, salt []byte) ([]byte, error) {
+	return pbkdf2.Key(sha256.New, password, salt, hashIterations, hashLength)
+}
+
+// key is how names are compared, "Alice" and "alice" are the same account
+func key(name string) string {
+	return strings.ToLower(name)
+}
@@ -0,0 +1,139 @@
+package tournament
+
+import "slices"
+
+func (t *Tournament) add(bracket Bracket, round int, a, b source) *Match {
+	m := &Match{ID: len(t.Matches) + 1, Bracket: bracket, Round: round, sources: [2]source{a, b}}
+	t.Matches = append(t.Matches, m)
+	return m
+}
+
+func winnerOf(m *Match) source { return source{from: m} }
+func loserOf(m *Match) source  { return source{from: m, loser: true} }
+
+// drawElimination lays out a knockout bracket padded to a power of two with
+// byes, the top seeds get the byes. A double elimination adds a losers
+// bracket and a grand final between the two brackets' winners.
+func (t *Tournament) drawElimination(double bool) {
+	size := 2
+	for size < len(t.Players) {
+		size *= 2
+	}
+
+	// round one, the seeds placed so 1 and 2 can only meet in the final
+	var round []*Match
+	order := seedOrder(size)
+	for i := 0; i < size; i += 2 {
+		round = append(round, t.add(Winners, 1, t.seed(order[i]), t.seed(order[i+1])))
+	}
+	t.winners = append(t.winners, round)
+
+	for len(round) > 1 {
+		var next []*Match
+		for i := 0; i < len(round); i += 2 {
+			next = append(next, t.add(Winners, len(t.winners)+1, winnerOf(round[i]), winnerOf(round[i+1])))
+		}
+		t.winners = append(t.winners, next)
+		round = next
+	}
+	t.final = round[0]
+
+	if !double {
+		return
+	}
+
+	// the losers bracket alternates between pairing off its own survivors and
+	// taking in the players who just dropped out of the winners bracket
+	var survivors []source
+	for _, m := range t.winners[0] {
+		survivors = append(survivors, loserOf(m))
+	}
+
+	if len(survivors) > 1 {
+		survivors = t.pairLosers(survivors)
+	}
+
+	for r := 1; r < len(t.winners); r++ {
+		var dropped []source
+		for _, m := range t.winners[r] {
+			dropped = append(dropped, loserOf(m))
+		}
+
+		// meet the other half of the bracket, not the player who just beat you
+		if r%2 == 1 {
+			slices.Reverse(dropped)
+		}
+
+		var next []source
+		var matches []*Match
+		for i := range survivors {
+			m := t.add(Losers, len(t.losers)+1, survivors[i], dropped[i])
+			matches = append(matches, m)
+			next = append(next, winnerOf(m))
+		}
+		t.losers = append(t.losers, matches)
+		survivors = next
+
+		if len(survivors) > 1 {
+			survivors = t.pairLosers(survivors)
+		}
+	}
+
+	t.final = t.add(GrandFinal, 1, winnerOf(t.final), survivors[0])
+}
+
+// pairLosers plays a round of the losers bracket among its survivors
+func (t *Tournament) pairLosers(survivors []source) []source {
+	var next []source
+	var matches []*Match
+	for i := 0; i < len(survivors); i += 2 {
+		m := t.add(Losers, len(t.losers)+1, survivors[i], survivors[i+1])
+		matches = append(matches, m)
+		next = append(next, winnerOf(m))
+	}
+	t.losers = append(t.losers, matches)
+	return next
+}
+
+// seed is the player for seed n counting from 1, or a bye past the last player
+func (t *Tournament) seed(n int) source {
+	if n > len(t.Players) {
+		return source{bye: true}
+	}
+	return source{player: t.Players[n-1]}
+}
+
+// seedOrder lists seeds in bracket order, 8 gives 1 8 4 5 2 7 3 6
+func seedOrder(size int) []int {
+	order := []int{1}
+	for len(order) < size {
+		next := make([]int, 0, len(order)*2)
+		for _, s := range order {
+			next = append(next, s, len(order)*2+1-s)
+		}
+		order = next
+	}
+	return order
+}
+
+// drawLeague pairs everyone with everyone by the circle method, so each round
+// has every player in at most one match
+func (t *Tournament) drawLeague() {
+	seats := slices.Clone(t.Players)
+	if len(seats)%2 == 1 {
+		seats = append(seats, "") // sitting out
+	}
+
+	n := len(seats)
+	for round := 1; round < n; round++ {
+		for i := 0; i < n/2; i++ {
+			a, b := seats[i], seats[n-1-i]
+			if a != "" && b != "" {
+				t.add(League, round, source{player: a}, source{player: b})
+			}
+		}
+
+		// keep the first seat, rotate the rest one place
+		seats = append([]string{seats[0], seats[n-1]}, seats[1:n-1]...)
+	}
+}
@@ -0,0 +1,203 @@
+package tournament
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// longest name drawn in the tree, longer ones are cut short
+const maxLabel = 12
+
+// Render draws the tournament as plain ASCII: the winners bracket as a tree,
+// the losers bracket and grand final round by round, and a round robin as a
+// table of standings and results
+func (t *Tournament) Render() string {
+	var out strings.Builder
+
+	out.WriteString(fmt.Sprintf("%s - %s, %d players\n", t.Name, t.Format, len(t.Players)))
+
+	if !t.Started {
+		out.WriteString("Registered: " + strings.Join(t.Players, ", ") + "\n")
+		return out.String()
+	}
+
+	if t.Format == RoundRobin {
+		t.renderLeague(&out)
+	} else {
+		out.WriteString("\n")
+		t.renderTree(&out)
+	}
+
+	if len(t.losers) > 0 {
+		out.WriteString("\nLosers bracket\n")
+		for i, round := range t.losers {
+			t.renderRound(&out, fmt.Sprintf("Round %d", i+1), round)
+		}
+		out.WriteString("\n")
+		t.renderRound(&out, "Grand final", []*Match{t.final})
+	}
+
+	var playing []string
+	for _, m := range t.Matches {
+		if m.Playing {
+			playing = append(playing, fmt.Sprintf("%s vs %s (%s)", m.Players[0], m.Players[1], t.RoundName(m)))
+		}
+	}
+	if len(playing) > 0 {
+		out.WriteString("\nNow playing: " + strings.Join(playing, ", ") + "\n")
+	}
+
+	if champion := t.Champion(); champion != "" {
+		out.WriteString("\nChampion: " + champion + "\n")
+	}
+
+	return out.String()
+}
+
+// renderTree draws the winners bracket with each round's entrants in a
+// column, joined to the player who came out of their match:
+//
+//	alice -+
+//	       +-alice -+
+//	bob   -+        |
+//	                +-?
+//	carol -+        |
+//	       +-?     -+
+//	dave  -+
+func (t *Tournament) renderTree(out *strings.Builder) {
+	width := 1
+	for _, name := range t.Players {
+		width = max(width, min(utf8.RuneCountInString(name), maxLabel))
+	}
+	width = max(width, len("(bye)"))
+	stride := width + 4
+
+	entrants := len(t.winners[0]) * 2
+	rows := make([][]rune, entrants*2-1)
+	for i := range rows {
+		rows[i] = []rune(strings.Repeat(" ", stride*(len(t.winners)+1)+width))
+	}
+
+	put := func(row, col int, text string) {
+		copy(rows[row][col:], []rune(text))
+	}
+
+	// lines holds the row each entrant of the current round is drawn on
+	lines := make([]int, entrants)
+	for i := range lines {
+		lines[i] = i * 2
+	}
+
+	for r, round := range t.winners {
+		col := r * stride
+		next := make([]int, len(round))
+
+		for i, m := range round {
+			top, bottom := lines[i*2], lines[i*2+1]
+			mid := (top + bottom) / 2
+
+			for side, row := range []int{top, bottom} {
+				put(row, col, fmt.Sprintf("%-*s -+", width, m.label(side)))
+			}
+			for row := top + 1; row < bottom; row++ {
+				put(row, col+width+2, "|")
+			}
+			put(mid, col+width+2, "+-")
+
+			next[i] = mid
+		}
+
+		lines = next
+	}
+
+	// the champion of the winners bracket, at the end of the last line
+	put(lines[0], len(t.winners)*stride, winnerLabel(t.winners[len(t.winners)-1][0]))
+
+	for _, row := range rows {
+		out.WriteString(strings.TrimRight(string(row), " ") + "\n")
+	}
+}
+
+// renderRound lists a round's matches, one per line
+func (t *Tournament) renderRound(out *strings.Builder, title string, round []*Match) {
+	out.WriteString("  " + title + ":\n")
+	for _, m := range round {
+		line := fmt.Sprintf("    #%d %s vs %s", m.ID, m.label(0), m.label(1))
+		if status := m.status(); status != "" {
+			line += "  - " + status
+		}
+		out.WriteString(line + "\n")
+	}
+}
+
+// renderLeague draws a round robin's standings, then its results round by round
+func (t *Tournament) renderLeague(out *strings.Builder) {
+	out.WriteString(fmt.Sprintf("\n  %-*s  W  L\n", maxLabel+4, "Standings"))
+	for i, standing := range t.Standings() {
+		name := clip(standing.Name)
+		if t.withdrawn[standing.Name] {
+			name += " (out)"
+		}
+		out.WriteString(fmt.Sprintf("  %2d. %-*s %2d %2d\n", i+1, maxLabel, name, standing.Wins, standing.Losses))
+	}
+
+	out.WriteString("\n")
+	for round := 1; ; round++ {
+		var matches []*Match
+		for _, m := range t.Matches {
+			if m.Round == round {
+				matches = append(matches, m)
+			}
+		}
+		if len(matches) == 0 {
+			break
+		}
+		t.renderRound(out, fmt.Sprintf("Round %d", round), matches)
+	}
+}
+
+// label is how one side of a match reads: a name, ? while it's open, or (bye)
+func (m *Match) label(side int) string {
+	switch {
+	case m.Players[side] != "":
+		return clip(m.Players[side])
+	case m.known[side] || m.Done:
+		return "(bye)"
+	default:
+		return "?"
+	}
+}
+
+func winnerLabel(m *Match) string {
+	if !m.Done {
+		return "?"
+	}
+	if m.Winner == "" {
+		return "(bye)"
+	}
+	return clip(m.Winner)
+}
+
+// status says where a match stands, for the round by round lists
+func (m *Match) status() string {
+	switch {
+	case m.Done && m.Winner == "":
+		return "nobody left to play"
+	case m.Walkover:
+		return m.Winner + " walks over"
+	case m.Done:
+		return m.Winner + " won"
+	case m.Playing:
+		return "playing now"
+	default:
+		return ""
+	}
+}
+
+func clip(name string) string {
+	if runes := []rune(name); len(runes) > maxLabel {
+		return string(runes[:maxLabel-1]) + "~"
+	}
+	return name
+}
@@ -0,0 +1,403 @@
+package tournament
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Format is how a tournament pairs its players
+type Format string
+
+const (
+	SingleElimination Format = "single-elimination"
+	DoubleElimination Format = "double-elimination" // a second loss knocks you out, the grand final is a single match
+	RoundRobin        Format = "round-robin"        // everyone plays everyone once, most wins takes it
+)
+
+// ParseFormat accepts a format's full name or its short form: single, double, rr
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(s) {
+	case "single", "single-elimination", "se":
+		return SingleElimination, nil
+	case "double", "double-elimination", "de":
+		return DoubleElimination, nil
+	case "round-robin", "roundrobin", "rr":
+		return RoundRobin, nil
+	}
+
+	return "", errors.New("unknown format " + s + ", expected single, double or round-robin")
+}
+
+var (
+	ErrStarted       = errors.New("the tournament has already started")
+	ErrNotStarted    = errors.New("the tournament hasn't started yet")
+	ErrRegistered    = errors.New("already registered")
+	ErrNotRegistered = errors.New("not registered")
+	ErrTooFewPlayers = errors.New("a tournament needs at least 2 players")
+	ErrNoSuchMatch   = errors.New("no such match")
+	ErrNotInMatch    = errors.New("that player isn't in this match")
+	ErrDecided       = errors.New("that match is already decided")
+)
+
+// Bracket says which part of a tournament a match belongs to
+type Bracket string
+
+const (
+	Winners    Bracket = "winners" // every match of a single elimination
+	Losers     Bracket = "losers"
+	GrandFinal Bracket = "grand-final"
+	League     Bracket = "league" // round robin
+)
+
+// Match is one pairing in a tournament. Players fill in as earlier matches
+// are decided, an empty name is a bye.
+type Match struct {
+	ID      int
+	Bracket Bracket
+	Round   int // from 1, counted within the bracket
+	Players [2]string
+	Winner  string
+	Done    bool
+	// decided without a game, against a bye or a withdrawn player
+	Walkover bool
+	Playing  bool // a game for it is under way, see Begin
+
+	sources [2]source
+	known   [2]bool // Players[i] is settled, even if it's a bye
+}
+
+// Ready reports whether m has two players and is waiting for its game
+func (m *Match) Ready() bool {
+	return !m.Done && !m.Playing && m.known[0] && m.known[1]
+}
+
+// Loser is whoever didn't win a decided match, empty for a walkover as
+// the player left out is a bye or has withdrawn
+func (m *Match) Loser() string {
+	if !m.Done || m.Walkover {
+		return ""
+	}
+	if m.Winner == m.Players[0] {
+		return m.Players[1]
+	}
+	return m.Players[0]
+}
+
+// source is where one of a match's players comes from: straight from the
+// seeding, or out of an earlier match
+type source struct {
+	player string
+	bye    bool
+	from   *Match
+	loser  bool // the loser of from rather than its winner
+}
+
+// Tournament is a bracket of matches between registered players. It only
+// keeps the score, whoever runs it plays the games and reports the winners.
+type Tournament struct {
+	Name    string
+	Format  Format
+	Players []string // in registration order, in seeding order once started
+	Matches []*Match
+	Started bool
+
+	withdrawn map[string]bool
+	winners   [][]*Match // by round, the whole bracket of a single elimination
+	losers    [][]*Match
+	final     *Match // decides the champion of an elimination
+}
+
+func New(name string, format Format) *Tournament {
+	return &Tournament{Name: name, Format: format, withdrawn: make(map[string]bool)}
+}
+
+// Register adds a player before the tournament starts
+func (t *Tournament) Register(name string) error {
+	if t.Started {
+		return ErrStarted
+	}
+	if t.Registered(name) {
+		return ErrRegistered
+	}
+
+	t.Players = append(t.Players, name)
+	return nil
+}
+
+// Unregister takes a player back out before the tournament starts,
+// afterwards they can only Withdraw
+func (t *Tournament) Unregister(name string) error {
+	if t.Started {
+		return ErrStarted
+	}
+
+	i := t.index(name)
+	if i < 0 {
+		return ErrNotRegistered
+	}
+
+	t.Players = slices.Delete(t.Players, i, i+1)
+	return nil
+}
+
+// Registered reports whether name is taking part, ignoring case
+func (t *Tournament) Registered(name string) bool {
+	return t.index(name) >= 0
+}
+
+func (t *Tournament) index(name string) int {
+	return slices.IndexFunc(t.Players, func(p string) bool {
+		return strings.EqualFold(p, name)
+	})
+}
+
+// Start draws the bracket from Players, the first seeded highest.
+// Matches against a bye are decided straight away.
+func (t *Tournament) Start() error {
+	if t.Started {
+		return ErrStarted
+	}
+	if len(t.Players) < 2 {
+		return ErrTooFewPlayers
+	}
+
+	switch t.Format {
+	case RoundRobin:
+		t.drawLeague()
+	case DoubleElimination:
+		t.drawElimination(true)
+	default:
+		t.drawElimination(false)
+	}
+
+	t.Started = true
+	t.resolve()
+
+	return nil
+}
+
+// Withdraw drops a player from a started tournament, every match they have
+// left, including one being played, goes to their opponent
+func (t *Tournament) Withdraw(name string) error {
+	if !t.Started {
+		return ErrNotStarted
+	}
+
+	i := t.index(name)
+	if i < 0 {
+		return ErrNotRegistered
+	}
+
+	t.withdrawn[t.Players[i]] = true
+	t.resolve()
+
+	return nil
+}
+
+// Withdrawn reports whether name has dropped out
+func (t *Tournament) Withdrawn(name string) bool {
+	i := t.index(name)
+	return i >= 0 && t.withdrawn[t.Players[i]]
+}
+
+// Ready lists the matches waiting for their game, in bracket order
+func (t *Tournament) Ready() []*Match {
+	var ready []*Match
+	for _, m := range t.Matches {
+		if m.Ready() {
+			ready = append(ready, m)
+		}
+	}
+	return ready
+}
+
+// Match finds a match by ID
+func (t *Tournament) Match(id int) *Match {
+	if id < 1 || id > len(t.Matches) {
+		return nil
+	}
+	return t.Matches[id-1]
+}
+
+// Begin marks a ready match as being played, so Ready leaves it out
+func (t *Tournament) Begin(m *Match) {
+	if m.Ready() {
+		m.Playing = true
+	}
+}
+
+// Report decides a match and moves the winner, and any loser with a second
+// chance, on through the bracket
+func (t *Tournament) Report(id int, winner string) error {
+	m := t.Match(id)
+	if m == nil {
+		return ErrNoSuchMatch
+	}
+	if m.Done {
+		return ErrDecided
+	}
+	if !m.known[0] || !m.known[1] {
+		return ErrNotInMatch
+	}
+
+	switch {
+	case strings.EqualFold(winner, m.Players[0]):
+		m.Winner = m.Players[0]
+	case strings.EqualFold(winner, m.Players[1]):
+		m.Winner = m.Players[1]
+	default:
+		return ErrNotInMatch
+	}
+
+	m.Done = true
+	m.Playing = false
+	t.resolve()
+
+	return nil
+}
+
+// Over reports whether every match is decided
+func (t *Tournament) Over() bool {
+	if !t.Started {
+		return false
+	}
+
+	for _, m := range t.Matches {
+		if !m.Done {
+			return false
+		}
+	}
+	return true
+}
+
+// Champion is the tournament's winner once it's over
+func (t *Tournament) Champion() string {
+	if !t.Over() {
+		return ""
+	}
+
+	if t.Format != RoundRobin {
+		return t.final.Winner
+	}
+
+	for _, standing := range t.Standings() {
+		if !t.withdrawn[standing.Name] {
+			return standing.Name
+		}
+	}
+	return ""
+}
+
+// Standing is one player's record in a round robin
+type Standing struct {
+	Name   string
+	Wins   int
+	Losses int
+}
+
+// Standings ranks players by wins, ties go to the earlier seed
+func (t *Tournament) Standings() []Standing {
+	standings := make([]Standing, len(t.Players))
+	for i, name := range t.Players {
+		standings[i].Name = name
+	}
+
+	for _, m := range t.Matches {
+		if !m.Done || m.Winner == "" {
+			continue
+		}
+		// a walkover is a win against somebody who withdrew
+		standings[t.index(m.Winner)].Wins++
+		if !m.Walkover {
+			standings[t.index(m.Loser())].Losses++
+		}
+	}
+
+	slices.SortStableFunc(standings, func(a, b Standing) int {
+		return b.Wins - a.Wins
+	})
+
+	return standings
+}
+
+// resolve fills in players as their sources are decided and settles every
+// match that has a bye or a withdrawn player in it, until nothing changes
+func (t *Tournament) resolve() {
+	for changed := true; changed; {
+		changed = false
+
+		for _, m := range t.Matches {
+			if m.Done {
+				continue
+			}
+
+			for i, src := range m.sources {
+				m.Players[i], m.known[i] = t.entrant(src)
+			}
+
+			absent := [2]bool{t.absent(m.Players[0]), t.absent(m.Players[1])}
+			if !m.known[0] || !m.known[1] || (!absent[0] && !absent[1]) {
+				continue
+			}
+
+			// whichever one is there, if either
+			m.Winner = ""
+			for i, name := range m.Players {
+				if !absent[i] {
+					m.Winner = name
+				}
+			}
+			m.Done, m.Walkover, m.Playing = true, true, false
+			changed = true
+		}
+	}
+}
+
+// entrant is who src sends into a match, known is false while that's still open
+func (t *Tournament) entrant(src source) (name string, known bool) {
+	switch {
+	case src.bye:
+		return "", true
+	case src.from == nil:
+		return src.player, true
+	case !src.from.Done:
+		return "", false
+	case src.loser:
+		return src.from.Loser(), true
+	default:
+		return src.from.Winner, true
+	}
+}
+
+// absent reports whether there's nobody to play in a seat, a bye or a withdrawn player
+func (t *Tournament) absent(name string) bool {
+	return name == "" || t.withdrawn[name]
+}
+
+// RoundName says where m sits in the tournament, e.g. "semi-final" or "losers round 2"
+func (t *Tournament) RoundName(m *Match) string {
+	switch m.Bracket {
+	case GrandFinal:
+		return "grand final"
+	case Losers:
+		return fmt.Sprintf("losers round %d", m.Round)
+	case League:
+		return fmt.Sprintf("round %d", m.Round)
+	}
+
+	prefix := ""
+	if t.Format == DoubleElimination {
+		prefix = "winners "
+	}
+
+	switch len(t.winners) - m.Round {
+	case 0:
+		return prefix + "final"
+	case 1:
+		return prefix + "semi-final"
+	default:
+		return fmt.Sprintf("%sround %d", prefix, m.Round)
+	}
+}
@@ -0,0 +1,330 @@
+package tournament
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+// entrants names n players by seed, p1 the top seed
+func entrants(n int) []string {
+	names := make([]string, n)
+	for i := range names {
+		names[i] = fmt.Sprintf("p%d", i+1)
+	}
+	return names
+}
+
+// started draws a tournament of format between players, seeded in order
+func started(t *testing.T, format Format, players []string) *Tournament {
+	t.Helper()
+
+	tt := New("Test Cup", format)
+	for _, name := range players {
+		if err := tt.Register(name); err != nil {
+			t.Fatalf("Register(%s): %v", name, err)
+		}
+	}
+	if err := tt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	return tt
+}
+
+// playOut reports every ready match with the winner pick chooses until the
+// tournament is over, failing if it stalls with matches left undecided
+func playOut(t *testing.T, tt *Tournament, pick func(m *Match) string) {
+	t.Helper()
+
+	for !tt.Over() {
+		ready := tt.Ready()
+		if len(ready) == 0 {
+			t.Fatalf("stalled with matches left:\n%s", tt.Render())
+		}
+
+		for _, m := range ready {
+			if err := tt.Report(m.ID, pick(m)); err != nil {
+				t.Fatalf("Report(%d, %s): %v", m.ID, pick(m), err)
+			}
+		}
+	}
+}
+
+// topSeed wins every match, the player seeded higher
+func topSeed(tt *Tournament) func(m *Match) string {
+	return func(m *Match) string {
+		if tt.index(m.Players[0]) < tt.index(m.Players[1]) {
+			return m.Players[0]
+		}
+		return m.Players[1]
+	}
+}
+
+// losses counts the games each player lost, walkovers left out
+func losses(tt *Tournament) map[string]int {
+	count := make(map[string]int)
+	for _, m := range tt.Matches {
+		if m.Done && !m.Walkover {
+			count[m.Loser()]++
+		}
+	}
+	return count
+}
+
+func TestSeedOrder(t *testing.T) {
+	if got, want := seedOrder(8), []int{1, 8, 4, 5, 2, 7, 3, 6}; !slices.Equal(got, want) {
+		t.Errorf("seedOrder(8) = %v, want %v", got, want)
+	}
+}
+
+func TestPlayThrough(t *testing.T) {
+	tests := []struct {
+		format  Format
+		players int
+		matches int // drawn, byes included
+		byes    int // walkovers settled at the draw, before any game is played
+	}{
+		{SingleElimination, 2, 1, 0},
+		{SingleElimination, 3, 3, 1},
+		{SingleElimination, 5, 7, 3},
+		{SingleElimination, 8, 7, 0},
+		{DoubleElimination, 2, 2, 0},
+		{DoubleElimination, 3, 6, 1},
+		{DoubleElimination, 5, 14, 4},
+		{DoubleElimination, 8, 14, 0},
+		{RoundRobin, 2, 1, 0},
+		{RoundRobin, 3, 3, 0},
+		{RoundRobin, 5, 10, 0},
+		{RoundRobin, 8, 28, 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(fmt.Sprintf("%s/%d", tc.format, tc.players), func(t *testing.T) {
+			players := entrants(tc.players)
+			tt := started(t, tc.format, players)
+
+			if len(tt.Matches) != tc.matches {
+				t.Fatalf("drew %d matches, want %d", len(tt.Matches), tc.matches)
+			}
+
+			byes := 0
+			for _, m := range tt.Matches {
+				if m.Walkover {
+					byes++
+				}
+			}
+			if byes != tc.byes {
+				t.Errorf("%d matches decided by a bye, want %d", byes, tc.byes)
+			}
+
+			playOut(t, tt, topSeed(tt))
+
+			if got := tt.Champion(); got != "p1" {
+				t.Errorf("champion %q, want p1", got)
+			}
+
+			lost := losses(tt)
+			for i, name := range players {
+				want := 1 // knocked out
+				switch {
+				case tc.format == RoundRobin:
+					want = i // beaten by everyone seeded higher
+				case name == "p1":
+					want = 0
+				case tc.format == DoubleElimination:
+					want = 2
+				}
+
+				if lost[name] != want {
+					t.Errorf("%s lost %d, want %d", name, lost[name], want)
+				}
+			}
+
+			if tc.format == RoundRobin {
+				// everyone meets everyone else exactly once
+				met := make(map[[2]string]int)
+				for _, m := range tt.Matches {
+					pair := m.Players
+					slices.Sort(pair[:])
+					met[pair]++
+				}
+				if len(met) != len(tt.Matches) {
+					t.Errorf("%d distinct pairings in %d matches", len(met), len(tt.Matches))
+				}
+
+				for i, standing := range tt.Standings() {
+					if standing.Name != players[i] || standing.Wins != len(players)-1-i {
+						t.Errorf("standing %d is %+v, want %s with %d wins", i+1, standing, players[i], len(players)-1-i)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestUpsetDropsToLosersBracket(t *testing.T) {
+	tt := started(t, DoubleElimination, entrants(4))
+
+	// round one is p1 v p4 and p2 v p3, p4 knocks out the top seed
+	for _, m := range tt.Ready() {
+		winner := "p2"
+		if m.Players == [2]string{"p1", "p4"} {
+			winner = "p4"
+		}
+		if err := tt.Report(m.ID, winner); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var losersRound *Match
+	for _, m := range tt.Ready() {
+		if m.Bracket == Losers {
+			losersRound = m
+		}
+	}
+	if losersRound == nil || losersRound.Players != [2]string{"p1", "p3"} {
+		t.Fatalf("losers bracket opens with %v, want p1 v p3", losersRound)
+	}
+
+	// p1 fights back through the losers bracket and takes the grand final
+	playOut(t, tt, topSeed(tt))
+
+	if got := tt.Champion(); got != "p1" {
+		t.Errorf("champion %q, want p1", got)
+	}
+	if got := tt.final.Players; got != [2]string{"p2", "p1"} {
+		t.Errorf("grand final %v, want p2 v p1", got)
+	}
+
+	lost := losses(tt)
+	for name, want := range map[string]int{"p1": 1, "p2": 1, "p3": 2, "p4": 2} {
+		if lost[name] != want {
+			t.Errorf("%s lost %d, want %d", name, lost[name], want)
+		}
+	}
+}
+
+func TestWithdrawMidBracket(t *testing.T) {
+	tt := started(t, SingleElimination, entrants(8))
+
+	// round one to the seeds
+	for _, m := range tt.Ready() {
+		if err := tt.Report(m.ID, topSeed(tt)(m)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	semi := tt.Ready()[0]
+	if semi.Players != [2]string{"p1", "p4"} {
+		t.Fatalf("first semi-final %v, want p1 v p4", semi.Players)
+	}
+	tt.Begin(semi)
+
+	// case doesn't matter, and the match under way is given up too
+	if err := tt.Withdraw("P1"); err != nil {
+		t.Fatal(err)
+	}
+	if !tt.Withdrawn("p1") {
+		t.Error("p1 isn't marked withdrawn")
+	}
+	if !semi.Done || !semi.Walkover || semi.Winner != "p4" || semi.Playing {
+		t.Errorf("semi-final after withdrawal %+v, want a walkover to p4", *semi)
+	}
+
+	if err := tt.Report(semi.ID, "p1"); err != ErrDecided {
+		t.Errorf("reporting a walkover = %v, want ErrDecided", err)
+	}
+
+	playOut(t, tt, topSeed(tt))
+
+	if got := tt.Champion(); got != "p2" {
+		t.Errorf("champion %q, want p2", got)
+	}
+}
+
+func TestWithdrawFromLeague(t *testing.T) {
+	tt := started(t, RoundRobin, entrants(3))
+
+	// p1 beats p3, then leaves with p2 still to play
+	for _, m := range tt.Matches {
+		if m.Players == [2]string{"p1", "p3"} {
+			if err := tt.Report(m.ID, "p1"); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := tt.Withdraw("p1"); err != nil {
+		t.Fatal(err)
+	}
+
+	playOut(t, tt, topSeed(tt))
+
+	// p1's walkover costs them no loss but they can't win it
+	if got := tt.Champion(); got != "p2" {
+		t.Errorf("champion %q, want p2", got)
+	}
+
+	want := []Standing{{"p2", 2, 0}, {"p1", 1, 0}, {"p3", 0, 2}}
+	if got := tt.Standings(); !slices.Equal(got, want) {
+		t.Errorf("standings %+v, want %+v", got, want)
+	}
+}
+
+func TestRegistration(t *testing.T) {
+	tt := New("Test Cup", SingleElimination)
+
+	if err := tt.Start(); err != ErrTooFewPlayers {
+		t.Errorf("Start with nobody = %v, want ErrTooFewPlayers", err)
+	}
+	if err := tt.Register("ann"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tt.Register("ANN"); err != ErrRegistered {
+		t.Errorf("registering twice = %v, want ErrRegistered", err)
+	}
+	if err := tt.Withdraw("ann"); err != ErrNotStarted {
+		t.Errorf("Withdraw before the start = %v, want ErrNotStarted", err)
+	}
+
+	tt.Register("bob")
+	tt.Start()
+
+	if err := tt.Register("cat"); err != ErrStarted {
+		t.Errorf("Register after the start = %v, want ErrStarted", err)
+	}
+	if err := tt.Report(1, "cat"); err != ErrNotInMatch {
+		t.Errorf("reporting an outsider = %v, want ErrNotInMatch", err)
+	}
+	if err := tt.Report(99, "ann"); err != ErrNoSuchMatch {
+		t.Errorf("reporting match 99 = %v, want ErrNoSuchMatch", err)
+	}
+}
+
+func TestRender(t *testing.T) {
+	tt := started(t, SingleElimination, []string{"alice", "bob", "carol"})
+
+	// alice has the bye, bob beats carol and the final is under way
+	for _, m := range tt.Ready() {
+		if err := tt.Report(m.ID, "bob"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tt.Begin(tt.Ready()[0])
+
+	const want = "" +
+		"Test Cup - single-elimination, 3 players\n" +
+		"\n" +
+		"alice -+\n" +
+		"       +-alice -+\n" +
+		"(bye) -+        |\n" +
+		"                +-?\n" +
+		"bob   -+        |\n" +
+		"       +-bob   -+\n" +
+		"carol -+\n" +
+		"\n" +
+		"Now playing: alice vs bob (final)\n"
+
+	if got := tt.Render(); got != want {
+		t.Errorf("Render() =\n%s\nwant\n%s", got, want)
+	}
+}